@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"rate-limiting-gateway/internal/metrics"
+)
+
+// runDumpMetrics implements `flowguard dump-metrics`: it walks the metrics
+// table and emits it as JSON so operators can diff the metrics surface
+// across releases in CI and generate Grafana dashboards from the dump.
+// With no arguments it writes to stdout; an optional path argument writes to
+// a file instead.
+func runDumpMetrics(args []string) error {
+	defs := metrics.NewMetrics(nil).Defs()
+
+	data, err := json.MarshalIndent(defs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics table: %w", err)
+	}
+
+	if len(args) == 0 {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(args[0], data, 0644); err != nil {
+		return fmt.Errorf("failed to write metrics dump to %s: %w", args[0], err)
+	}
+
+	return nil
+}
@@ -3,44 +3,56 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"rate-limiting-gateway/internal/cluster"
 	"rate-limiting-gateway/internal/config"
 	"rate-limiting-gateway/internal/handlers"
 	"rate-limiting-gateway/internal/limiter"
+	"rate-limiting-gateway/internal/logging"
 	"rate-limiting-gateway/internal/metrics"
 	"rate-limiting-gateway/internal/middleware"
 	"rate-limiting-gateway/internal/storage"
+	"rate-limiting-gateway/internal/upstream"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	// Initialize logger
-	logger, err := initLogger()
-	if err != nil {
-		fmt.Printf("Failed to initialize logger: %v\n", err)
-		os.Exit(1)
+	// Subcommand dispatch: `flowguard dump-metrics [path]` emits the metrics
+	// table as JSON instead of starting the gateway.
+	if len(os.Args) > 1 && os.Args[1] == "dump-metrics" {
+		if err := runDumpMetrics(os.Args[2:]); err != nil {
+			fmt.Printf("dump-metrics failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-	defer logger.Sync()
-
-	logger.Info("Starting rate limiting gateway...")
 
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		logger.Fatal("Failed to load configuration", zap.Error(err))
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
 	}
 
 	if err := cfg.Validate(); err != nil {
-		logger.Fatal("Invalid configuration", zap.Error(err))
+		fmt.Printf("Invalid configuration: %v\n", err)
+		os.Exit(1)
 	}
 
+	// Initialize logger
+	logger := initLogger(cfg.Logging)
+
+	logger.Info("Starting rate limiting gateway...")
+
 	// Initialize Redis client
 	redisClient, err := storage.NewRedisClient(
 		cfg.Redis.Addr,
@@ -50,7 +62,8 @@ func main() {
 		logger,
 	)
 	if err != nil {
-		logger.Fatal("Failed to connect to Redis", zap.Error(err))
+		logger.Error("Failed to connect to Redis", slog.Any("error", err))
+		os.Exit(1)
 	}
 	defer redisClient.Close()
 
@@ -61,26 +74,132 @@ func main() {
 		RequestsPerDay:    cfg.RateLimit.RequestsPerDay,
 		MonthlyQuota:      cfg.RateLimit.MonthlyQuota,
 		WindowSize:        cfg.RateLimit.WindowSize,
+		BypassKeys:        cfg.RateLimit.BypassKeys,
+		BypassCIDRs:       cfg.RateLimit.BypassCIDRs,
 	}, logger)
 
+	if cfg.GRPC.DescriptorConfigPath != "" {
+		descriptorConfig, err := limiter.LoadDescriptorConfig(cfg.GRPC.DescriptorConfigPath)
+		if err != nil {
+			logger.Error("Failed to load descriptor config", slog.Any("error", err))
+			os.Exit(1)
+		}
+		rateLimiter.SetDescriptorConfig(descriptorConfig)
+	}
+
+	if cfg.RateLimit.PolicyConfigPath != "" {
+		policyStore, err := limiter.LoadPolicyConfig(cfg.RateLimit.PolicyConfigPath)
+		if err != nil {
+			logger.Error("Failed to load policy config", slog.Any("error", err))
+			os.Exit(1)
+		}
+		rateLimiter.SetPolicyStore(policyStore)
+
+		if cfg.RateLimit.PolicyReloadInterval > 0 {
+			go limiter.WatchPolicyConfig(context.Background(), policyStore, cfg.RateLimit.PolicyConfigPath, cfg.RateLimit.PolicyReloadInterval, logger)
+		}
+	}
+
+	// Wire up the pluggable per-client tier backend, if configured, behind
+	// an LRU+TTL cache so a hot key doesn't hit it on every request.
+	var keyStore storage.KeyStore
+	switch cfg.KeyStore.Backend {
+	case "postgres":
+		pgKeyStore, err := storage.NewPostgresKeyStore(cfg.KeyStore.PostgresDSN)
+		if err != nil {
+			logger.Error("Failed to connect to client key store", slog.Any("error", err))
+			os.Exit(1)
+		}
+		keyStore = pgKeyStore
+	case "redis":
+		keyStore = storage.NewRedisKeyStore(redisClient)
+	}
+	if keyStore != nil {
+		keyStore = storage.NewCachedKeyStore(keyStore, cfg.KeyStore.CacheSize, cfg.KeyStore.CacheTTL)
+		rateLimiter.SetKeyStore(keyStore)
+	}
+
+	// Wire up peer-based distributed rate limiting, if enabled, so Redis
+	// stops being a single point of contention under high QPS.
+	var peerCluster *cluster.Cluster
+	if cfg.Cluster.Enabled {
+		resolver := cluster.Resolver(cluster.NewStaticResolver(cfg.Cluster.Peers))
+		counter := cluster.NewInMemoryCounter(redisClient, 30*time.Second)
+		peerCluster = cluster.NewCluster(cfg.Cluster.SelfAddr, resolver, counter, logger)
+		peerCluster.Start(context.Background(), cfg.Cluster.GossipInterval)
+		rateLimiter.SetCluster(peerCluster)
+
+		go startPeerServer(cfg, peerCluster, logger)
+	}
+
 	// Initialize metrics
 	metricsInstance := metrics.NewMetrics(logger)
+	rateLimiter.SetMetrics(metricsInstance)
+
+	// Initialize per-upstream circuit breaking, alongside the per-client
+	// rate limiter above.
+	backendLimiter := limiter.NewBackendLimiter(redisClient, limiter.DefaultBackendLimiterConfig(), logger)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(redisClient, logger)
+	if keyStore != nil {
+		authMiddleware.SetKeyStore(keyStore)
+	}
+	authMiddleware.SetRateLimiter(rateLimiter)
+	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(cfg.Admin.Token, logger)
+	if !adminAuthMiddleware.Enabled() {
+		logger.Warn("ADMIN_AUTH_TOKEN is not set; /admin/* routes are disabled")
+	}
+	backendMiddleware := middleware.NewBackendMiddleware(backendLimiter, cfg.Target.URL, logger)
+	concurrencyMiddleware := middleware.NewConcurrencyMiddleware(cfg.Concurrency, metricsInstance, logger)
 	rateLimitMiddleware := middleware.NewRateLimitMiddleware(rateLimiter, logger)
 	loggingMiddleware := middleware.NewLoggingMiddleware(logger)
+	frontendMiddleware := middleware.NewFrontendMiddleware(cfg.RateLimit, logger)
 
 	// Initialize handlers
 	gatewayHandler := handlers.NewGatewayHandler(cfg, rateLimiter, logger)
+	if cfg.Upstream.RouteConfigPath != "" {
+		upstreamRouter, err := upstream.LoadRouteConfig(cfg.Upstream.RouteConfigPath, logger)
+		if err != nil {
+			logger.Error("Failed to load upstream route config", slog.Any("error", err))
+			os.Exit(1)
+		}
+		gatewayHandler.SetRouter(upstreamRouter)
+		backendMiddleware.SetRouter(upstreamRouter)
+	}
+	var adminHandler *handlers.AdminHandler
+	if keyStore != nil {
+		adminHandler = handlers.NewAdminHandler(keyStore, logger)
+	}
+	bypassHandler := handlers.NewBypassHandler(redisClient, logger)
+	adminBackendHandler := handlers.NewAdminBackendHandler(backendLimiter, logger)
+
+	// Start the Envoy-compatible gRPC RateLimitService alongside the HTTP gateway
+	if cfg.GRPC.Port != "" {
+		go startGRPCServer(cfg, rateLimiter, logger)
+	}
+
+	// When ADMIN_PORT is set, /admin/* is served on its own listener instead
+	// of the public gateway port, so it can sit behind a separate, internal
+	// -only network ACL; otherwise it's mounted on the public router, still
+	// gated by adminAuthMiddleware.
+	separateAdminPort := cfg.Admin.Port != ""
 
 	// Setup Gin router
 	router := setupRouter(
 		authMiddleware,
+		backendMiddleware,
+		concurrencyMiddleware,
 		rateLimitMiddleware,
 		loggingMiddleware,
+		frontendMiddleware,
 		gatewayHandler,
+		adminAuthMiddleware,
+		adminHandler,
+		bypassHandler,
+		adminBackendHandler,
 		metricsInstance,
+		!separateAdminPort,
 	)
 
 	// Create HTTP server
@@ -94,12 +213,29 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		logger.Info("Starting HTTP server", zap.String("port", cfg.Server.Port))
+		logger.Info("Starting HTTP server", slog.String("port", cfg.Server.Port))
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Failed to start server", zap.Error(err))
+			logger.Error("Failed to start server", slog.Any("error", err))
+			os.Exit(1)
 		}
 	}()
 
+	var adminServer *http.Server
+	if separateAdminPort {
+		adminRouter := setupAdminRouter(adminAuthMiddleware, adminHandler, bypassHandler, adminBackendHandler)
+		adminServer = &http.Server{
+			Addr:    ":" + cfg.Admin.Port,
+			Handler: adminRouter,
+		}
+		go func() {
+			logger.Info("Starting admin HTTP server", slog.String("port", cfg.Admin.Port))
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("Failed to start admin server", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -112,7 +248,14 @@ func main() {
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown", zap.Error(err))
+		logger.Error("Server forced to shutdown", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			logger.Error("Admin server forced to shutdown", slog.Any("error", err))
+		}
 	}
 
 	logger.Info("Server exited")
@@ -121,10 +264,18 @@ func main() {
 // setupRouter configures the Gin router with all middleware and routes
 func setupRouter(
 	authMiddleware *middleware.AuthMiddleware,
+	backendMiddleware *middleware.BackendMiddleware,
+	concurrencyMiddleware *middleware.ConcurrencyMiddleware,
 	rateLimitMiddleware *middleware.RateLimitMiddleware,
 	loggingMiddleware *middleware.LoggingMiddleware,
+	frontendMiddleware *middleware.FrontendMiddleware,
 	gatewayHandler *handlers.GatewayHandler,
+	adminAuthMiddleware *middleware.AdminAuthMiddleware,
+	adminHandler *handlers.AdminHandler,
+	bypassHandler *handlers.BypassHandler,
+	adminBackendHandler *handlers.AdminBackendHandler,
 	metricsInstance *metrics.Metrics,
+	mountAdmin bool,
 ) *gin.Engine {
 	// Set Gin mode
 	gin.SetMode(gin.ReleaseMode)
@@ -135,6 +286,7 @@ func setupRouter(
 	router.Use(gin.Recovery())
 	router.Use(loggingMiddleware.LogRequest())
 	router.Use(loggingMiddleware.LogError())
+	router.Use(frontendMiddleware.RateLimit())
 
 	// Health check endpoint (no auth required)
 	router.GET("/health", gatewayHandler.HealthCheck)
@@ -146,7 +298,7 @@ func setupRouter(
 	api := router.Group("/api")
 	{
 		// Rate limit info endpoint
-		api.GET("/rate-limit-info", 
+		api.GET("/rate-limit-info",
 			authMiddleware.Authenticate(),
 			gatewayHandler.GetRateLimitInfo,
 		)
@@ -156,33 +308,133 @@ func setupRouter(
 	proxy := router.Group("/proxy")
 	{
 		proxy.Use(authMiddleware.Authenticate())
+		proxy.Use(concurrencyMiddleware.Limit())
+		proxy.Use(backendMiddleware.Check())
 		proxy.Use(rateLimitMiddleware.RateLimit())
-		proxy.Use(rateLimitMiddleware.IncrementRateLimit())
-		
+
 		// Catch-all route for proxying
 		proxy.Any("/*path", gatewayHandler.Proxy)
 	}
 
+	// Admin routes are mounted here only when ADMIN_PORT is unset; otherwise
+	// they live exclusively on the dedicated admin router (see
+	// setupAdminRouter) and must not also be reachable from the public port.
+	if mountAdmin {
+		registerAdminRoutes(router, adminAuthMiddleware, adminHandler, bypassHandler, adminBackendHandler)
+	}
+
 	return router
 }
 
-// initLogger initializes the Zap logger
-func initLogger() (*zap.Logger, error) {
-	config := zap.NewProductionConfig()
-	config.OutputPaths = []string{"stdout"}
-	config.ErrorOutputPaths = []string{"stderr"}
-	
-	// Set log level based on environment
-	if os.Getenv("LOG_LEVEL") != "" {
-		if err := config.Level.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
-			return nil, fmt.Errorf("invalid log level: %w", err)
+// setupAdminRouter builds the dedicated router served on ADMIN_PORT,
+// carrying only the /admin/* groups — no public proxy/API routes, so a
+// network ACL around this listener is the only thing standing between the
+// admin surface and the internet, not accidental exposure through the same
+// router as /proxy.
+func setupAdminRouter(
+	adminAuthMiddleware *middleware.AdminAuthMiddleware,
+	adminHandler *handlers.AdminHandler,
+	bypassHandler *handlers.BypassHandler,
+	adminBackendHandler *handlers.AdminBackendHandler,
+) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	registerAdminRoutes(router, adminAuthMiddleware, adminHandler, bypassHandler, adminBackendHandler)
+	return router
+}
+
+// registerAdminRoutes wires the /admin/clients, /admin/bypass, and
+// /admin/backends groups onto r, each gated by adminAuthMiddleware so a
+// regular API key (or no credential at all) can never reach them.
+func registerAdminRoutes(
+	r gin.IRouter,
+	adminAuthMiddleware *middleware.AdminAuthMiddleware,
+	adminHandler *handlers.AdminHandler,
+	bypassHandler *handlers.BypassHandler,
+	adminBackendHandler *handlers.AdminBackendHandler,
+) {
+	// Admin routes for CRUD over the pluggable API-key backend, when one is
+	// configured.
+	if adminHandler != nil {
+		admin := r.Group("/admin/clients")
+		admin.Use(adminAuthMiddleware.Authenticate())
+		{
+			admin.GET("/:api_key", adminHandler.GetClient)
+			admin.PUT("/:api_key", adminHandler.UpsertClient)
+			admin.DELETE("/:api_key", adminHandler.DeleteClient)
+			admin.POST("", adminHandler.UpsertClient)
 		}
 	}
 
-	// Set JSON logging based on environment
-	if os.Getenv("LOG_JSON") == "false" {
-		config.Encoding = "console"
+	// Admin routes for the dynamic rate-limit bypass set.
+	bypass := r.Group("/admin/bypass")
+	bypass.Use(adminAuthMiddleware.Authenticate())
+	{
+		bypass.POST("", bypassHandler.AddBypassKey)
+		bypass.DELETE("/:api_key", bypassHandler.RemoveBypassKey)
+	}
+
+	// Admin routes for inspecting and overriding per-backend circuit
+	// breaker state.
+	adminBackends := r.Group("/admin/backends")
+	adminBackends.Use(adminAuthMiddleware.Authenticate())
+	{
+		adminBackends.GET("/:backend", adminBackendHandler.GetBackend)
+		adminBackends.POST("/:backend", adminBackendHandler.SetBackendOverride)
+	}
+}
+
+// startGRPCServer runs the Envoy RateLimitService on its own port so Envoy
+// sidecars can call flowguard directly instead of going through the HTTP proxy.
+func startGRPCServer(cfg *config.Config, rateLimiter limiter.RateLimiterInterface, logger *slog.Logger) {
+	lis, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+	if err != nil {
+		logger.Error("Failed to listen for gRPC", slog.String("port", cfg.GRPC.Port), slog.Any("error", err))
+		return
+	}
+
+	server := grpc.NewServer(limiter.GRPCServerOptions()...)
+	limiter.NewRateLimitServiceServer(rateLimiter, logger).Register(server)
+
+	logger.Info("Starting gRPC RateLimitService", slog.String("port", cfg.GRPC.Port))
+	if err := server.Serve(lis); err != nil {
+		logger.Error("gRPC server stopped", slog.Any("error", err))
+	}
+}
+
+// startPeerServer runs the inter-node PeerService gRPC server that other
+// cluster members forward owned-key requests to.
+func startPeerServer(cfg *config.Config, peerCluster *cluster.Cluster, logger *slog.Logger) {
+	lis, err := net.Listen("tcp", ":"+cfg.Cluster.PeerPort)
+	if err != nil {
+		logger.Error("Failed to listen for peer gRPC", slog.String("port", cfg.Cluster.PeerPort), slog.Any("error", err))
+		return
 	}
 
-	return config.Build()
-} 
\ No newline at end of file
+	server := grpc.NewServer(cluster.GRPCServerOptions()...)
+	peerCluster.Register(server)
+
+	logger.Info("Starting cluster PeerService", slog.String("port", cfg.Cluster.PeerPort))
+	if err := server.Serve(lis); err != nil {
+		logger.Error("Peer gRPC server stopped", slog.Any("error", err))
+	}
+}
+
+// initLogger builds the application's slog.Logger from LoggingConfig,
+// wrapped in a DedupHandler so a single misbehaving client hammering the
+// limiter doesn't spam the log stream with near-identical rejection records.
+func initLogger(cfg config.LoggingConfig) *slog.Logger {
+	level := slog.LevelInfo
+	_ = level.UnmarshalText([]byte(cfg.Level))
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if cfg.JSON {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	return slog.New(logging.NewDedupHandler(handler, time.Second))
+}
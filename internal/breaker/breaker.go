@@ -0,0 +1,181 @@
+// Package breaker implements a simple per-backend circuit breaker, used by
+// limiter.BackendLimiter to fail fast against an upstream that's throwing
+// errors instead of piling retries onto it.
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is a circuit breaker's current state.
+type State int
+
+const (
+	// Closed is the normal state: requests pass through and are counted
+	// toward the rolling error rate.
+	Closed State = iota
+	// Open means the backend is considered unhealthy; requests fail fast
+	// until OpenDuration has elapsed.
+	Open
+	// HalfOpen means the backend is being cautiously probed for recovery
+	// after OpenDuration; callers are expected to cap concurrency further
+	// while in this state (see limiter.BackendLimiter.Before).
+	HalfOpen
+)
+
+// String returns the state's lowercase name, as used in /admin/backends
+// responses.
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// Config controls when a CircuitBreaker trips and how it recovers.
+type Config struct {
+	// Window is how long the Closed-state error/total counters accumulate
+	// before resetting. This is a fixed window, not a true sliding one —
+	// the same simplicity tradeoff the cluster package makes for its
+	// fixed-window rate counters.
+	Window time.Duration
+	// MinRequests is the minimum sample size within Window before the error
+	// rate is evaluated, so one unlucky early failure doesn't trip the
+	// breaker on its own.
+	MinRequests int
+	// ErrorThreshold is the fraction of failed requests (0-1) within Window
+	// that trips the breaker from Closed to Open.
+	ErrorThreshold float64
+	// OpenDuration is how long the breaker stays Open before Allow moves it
+	// to HalfOpen, where requests are let through again (Allow does not cap
+	// how many).
+	OpenDuration time.Duration
+	// HalfOpenSuccesses is how many consecutive successes while HalfOpen
+	// are required to close the breaker again.
+	HalfOpenSuccesses int
+}
+
+// DefaultConfig returns reasonable defaults for a backend circuit breaker.
+func DefaultConfig() Config {
+	return Config{
+		Window:            10 * time.Second,
+		MinRequests:       20,
+		ErrorThreshold:    0.5,
+		OpenDuration:      30 * time.Second,
+		HalfOpenSuccesses: 5,
+	}
+}
+
+// CircuitBreaker tracks one backend's rolling error rate and gates whether
+// requests should be let through, failed fast, or cautiously probed.
+type CircuitBreaker struct {
+	mu     sync.Mutex
+	config Config
+
+	state      State
+	windowFrom time.Time
+	total      int
+	errors     int
+
+	openedAt        time.Time
+	halfOpenSuccess int
+}
+
+// NewCircuitBreaker creates a new circuit breaker in the Closed state.
+func NewCircuitBreaker(config Config) *CircuitBreaker {
+	return &CircuitBreaker{
+		config:     config,
+		state:      Closed,
+		windowFrom: time.Now(),
+	}
+}
+
+// Allow reports whether a request should be let through, advancing Open to
+// HalfOpen once OpenDuration has elapsed. Callers that get true back from a
+// HalfOpen breaker are expected to additionally cap their own concurrency
+// (see limiter.BackendLimiter.Before) since Allow alone doesn't limit how
+// many concurrent probes go through.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open {
+		if time.Since(b.openedAt) >= b.config.OpenDuration {
+			b.state = HalfOpen
+			b.halfOpenSuccess = 0
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// RecordResult reports the outcome of a request Allow let through.
+func (b *CircuitBreaker) RecordResult(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case HalfOpen:
+		if !success {
+			b.trip()
+			return
+		}
+		b.halfOpenSuccess++
+		if b.halfOpenSuccess >= b.config.HalfOpenSuccesses {
+			b.close()
+		}
+	case Closed:
+		if time.Since(b.windowFrom) >= b.config.Window {
+			b.total, b.errors = 0, 0
+			b.windowFrom = time.Now()
+		}
+		b.total++
+		if !success {
+			b.errors++
+		}
+		if b.total >= b.config.MinRequests && float64(b.errors)/float64(b.total) >= b.config.ErrorThreshold {
+			b.trip()
+		}
+	}
+}
+
+func (b *CircuitBreaker) trip() {
+	b.state = Open
+	b.openedAt = time.Now()
+}
+
+func (b *CircuitBreaker) close() {
+	b.state = Closed
+	b.total, b.errors = 0, 0
+	b.windowFrom = time.Now()
+}
+
+// State reports the breaker's current state.
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// ForceOpen trips the breaker regardless of its observed error rate, for an
+// operator-initiated circuit break.
+func (b *CircuitBreaker) ForceOpen() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trip()
+}
+
+// ForceClose resets the breaker to Closed regardless of its observed error
+// rate, for an operator clearing a breaker once they've confirmed the
+// backend has recovered.
+func (b *CircuitBreaker) ForceClose() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.close()
+}
@@ -0,0 +1,100 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_TripsOpenOnceErrorThresholdAndMinRequestsAreMet(t *testing.T) {
+	b := NewCircuitBreaker(Config{
+		Window:         time.Minute,
+		MinRequests:    4,
+		ErrorThreshold: 0.5,
+	})
+
+	b.RecordResult(false)
+	b.RecordResult(false)
+	b.RecordResult(true)
+	assert.Equal(t, Closed, b.State(), "below MinRequests, the breaker must not evaluate the error rate yet")
+
+	b.RecordResult(true)
+	assert.Equal(t, Open, b.State(), "2/4 failures meets the 0.5 threshold once MinRequests is reached")
+}
+
+func TestCircuitBreaker_StaysClosedBelowErrorThreshold(t *testing.T) {
+	b := NewCircuitBreaker(Config{
+		Window:         time.Minute,
+		MinRequests:    4,
+		ErrorThreshold: 0.5,
+	})
+
+	b.RecordResult(false)
+	b.RecordResult(true)
+	b.RecordResult(true)
+	b.RecordResult(true)
+
+	assert.Equal(t, Closed, b.State(), "1/4 failures is below the 0.5 threshold")
+}
+
+func TestCircuitBreaker_AllowFailsFastWhileOpen(t *testing.T) {
+	b := NewCircuitBreaker(Config{OpenDuration: time.Hour})
+	b.ForceOpen()
+
+	assert.False(t, b.Allow())
+	assert.Equal(t, Open, b.State())
+}
+
+func TestCircuitBreaker_AllowMovesToHalfOpenAfterOpenDuration(t *testing.T) {
+	b := NewCircuitBreaker(Config{OpenDuration: time.Millisecond})
+	b.ForceOpen()
+	time.Sleep(5 * time.Millisecond)
+
+	assert.True(t, b.Allow(), "Allow should admit a single probe once OpenDuration has elapsed")
+	assert.Equal(t, HalfOpen, b.State())
+}
+
+func TestCircuitBreaker_HalfOpenReopensOnAnyFailure(t *testing.T) {
+	b := NewCircuitBreaker(Config{OpenDuration: time.Millisecond, HalfOpenSuccesses: 3})
+	b.ForceOpen()
+	time.Sleep(5 * time.Millisecond)
+	b.Allow() // advance Open -> HalfOpen
+
+	b.RecordResult(false)
+
+	assert.Equal(t, Open, b.State(), "a single failed probe while HalfOpen must reopen the breaker")
+}
+
+func TestCircuitBreaker_HalfOpenClosesAfterEnoughConsecutiveSuccesses(t *testing.T) {
+	b := NewCircuitBreaker(Config{OpenDuration: time.Millisecond, HalfOpenSuccesses: 2})
+	b.ForceOpen()
+	time.Sleep(5 * time.Millisecond)
+	b.Allow() // advance Open -> HalfOpen
+
+	b.RecordResult(true)
+	assert.Equal(t, HalfOpen, b.State(), "one success is not yet enough to close")
+
+	b.RecordResult(true)
+	assert.Equal(t, Closed, b.State(), "HalfOpenSuccesses consecutive successes should close the breaker")
+}
+
+func TestCircuitBreaker_ForceCloseResetsCountersForFutureTrips(t *testing.T) {
+	b := NewCircuitBreaker(Config{
+		Window:         time.Minute,
+		MinRequests:    2,
+		ErrorThreshold: 0.5,
+	})
+
+	b.RecordResult(false)
+	b.RecordResult(false)
+	assert.Equal(t, Open, b.State())
+
+	b.ForceClose()
+	assert.Equal(t, Closed, b.State())
+
+	// After ForceClose, the error window must have been reset: one failure
+	// alone shouldn't retrip the breaker below MinRequests.
+	b.RecordResult(false)
+	assert.Equal(t, Closed, b.State())
+}
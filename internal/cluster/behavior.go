@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	"sync"
+	"time"
+)
+
+// Behavior controls how a rate-limit key's increments are propagated to its
+// owner peer, mirroring Gubernator's global-batch mode.
+type Behavior int
+
+const (
+	// NoBatching forwards every increment to the owner immediately.
+	NoBatching Behavior = iota
+	// Batching aggregates increments locally and flushes them to the owner
+	// on a short interval, trading a small amount of over-limit slack for
+	// far fewer network round trips under high QPS.
+	Batching
+	// Global selects the same handling as Batching: increments are
+	// aggregated locally and flushed to the owner on the batch interval.
+	// Nothing in this package treats it differently from Batching.
+	Global
+)
+
+// Batcher accumulates local increments for batched/global keys and flushes
+// them to the owning peer on a fixed interval.
+type Batcher struct {
+	mu       sync.Mutex
+	pending  map[string]int
+	interval time.Duration
+	flush    func(key string, delta int)
+	stopCh   chan struct{}
+}
+
+// NewBatcher creates a batcher that flushes accumulated deltas to flushFn
+// every interval.
+func NewBatcher(interval time.Duration, flushFn func(key string, delta int)) *Batcher {
+	b := &Batcher{
+		pending:  make(map[string]int),
+		interval: interval,
+		flush:    flushFn,
+		stopCh:   make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Add accumulates a pending increment for key, to be flushed on the next tick.
+func (b *Batcher) Add(key string, delta int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending[key] += delta
+}
+
+// Stop halts the flush loop.
+func (b *Batcher) Stop() {
+	close(b.stopCh)
+}
+
+func (b *Batcher) run() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.flushAll()
+		case <-b.stopCh:
+			b.flushAll()
+			return
+		}
+	}
+}
+
+func (b *Batcher) flushAll() {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = make(map[string]int)
+	b.mu.Unlock()
+
+	for key, delta := range pending {
+		if delta != 0 {
+			b.flush(key, delta)
+		}
+	}
+}
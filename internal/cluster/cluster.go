@@ -0,0 +1,247 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Counter is the in-memory counter store an owner peer keeps for the keys it
+// owns, with periodic Redis persistence as a fallback handled by the caller.
+type Counter interface {
+	Get(ctx context.Context, key, window string) (int, error)
+	Increment(ctx context.Context, key, window string, limit int) (int, error)
+}
+
+// Cluster tracks cluster membership via a consistent hash ring and forwards
+// requests for keys owned by other peers over gRPC, so a flowguard
+// deployment can run as N peers without Redis being a single point of
+// contention.
+type Cluster struct {
+	self     string
+	ring     *HashRing
+	resolver Resolver
+	counter  Counter
+	logger   *slog.Logger
+
+	mu      sync.RWMutex
+	clients map[string]peerService
+
+	stopCh chan struct{}
+}
+
+// NewCluster creates a cluster coordinator. self is this node's own
+// dial-from-peers address (host:port of its gRPC peer listener).
+func NewCluster(self string, resolver Resolver, counter Counter, logger *slog.Logger) *Cluster {
+	c := &Cluster{
+		self:     self,
+		ring:     NewHashRing(),
+		resolver: resolver,
+		counter:  counter,
+		logger:   logger,
+		clients:  make(map[string]peerService),
+		stopCh:   make(chan struct{}),
+	}
+	return c
+}
+
+// Start launches the background gossip loop that periodically re-resolves
+// membership and health-checks peers, refreshing the hash ring as it goes.
+func (c *Cluster) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		c.refresh(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.refresh(ctx)
+			case <-c.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the gossip loop and closes peer connections.
+func (c *Cluster) Stop() {
+	close(c.stopCh)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for peer, client := range c.clients {
+		if g, ok := client.(*grpcPeerClient); ok {
+			g.conn.Close()
+		}
+		delete(c.clients, peer)
+	}
+}
+
+func (c *Cluster) refresh(ctx context.Context) {
+	peers, err := c.resolver.Resolve(ctx)
+	if err != nil {
+		c.logger.Warn("Failed to resolve cluster membership", slog.Any("error", err))
+		return
+	}
+
+	healthy := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		if peer == c.self || c.ping(ctx, peer) {
+			healthy = append(healthy, peer)
+		} else {
+			c.logger.Warn("Peer failed health check, excluding from ring", slog.String("peer", peer))
+		}
+	}
+
+	c.ring.SetPeers(healthy)
+}
+
+func (c *Cluster) ping(ctx context.Context, peer string) bool {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	client, err := c.clientFor(peer)
+	if err != nil {
+		return false
+	}
+	_, err = client.CheckRateLimit(ctx, &PeerRequest{APIKey: "__health__", Window: "health", Limit: 1})
+	return err == nil
+}
+
+// Owner returns the peer address that owns key, and whether that peer is
+// this node itself.
+func (c *Cluster) Owner(key string) (peer string, isLocal bool) {
+	owner, ok := c.ring.Owner(key)
+	if !ok {
+		// No known peers yet; treat every key as locally owned.
+		return c.self, true
+	}
+	return owner, owner == c.self
+}
+
+// CheckRateLimit returns the current counter for key/window, querying the
+// owning peer over gRPC when it isn't this node.
+func (c *Cluster) CheckRateLimit(ctx context.Context, key, window string) (int, error) {
+	owner, isLocal := c.Owner(key)
+	if isLocal {
+		return c.counter.Get(ctx, key, window)
+	}
+
+	client, err := c.clientFor(owner)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.CheckRateLimit(ctx, &PeerRequest{APIKey: key, Window: window})
+	if err != nil {
+		return 0, fmt.Errorf("forward CheckRateLimit to %s: %w", owner, err)
+	}
+	return resp.Current, nil
+}
+
+// IncrementRateLimit increments the counter for key/window, forwarding to the
+// owning peer over gRPC when it isn't this node.
+func (c *Cluster) IncrementRateLimit(ctx context.Context, key, window string, limit int) (int, error) {
+	owner, isLocal := c.Owner(key)
+	if isLocal {
+		return c.counter.Increment(ctx, key, window, limit)
+	}
+
+	client, err := c.clientFor(owner)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.IncrementRateLimit(ctx, &PeerRequest{APIKey: key, Window: window, Limit: limit})
+	if err != nil {
+		return 0, fmt.Errorf("forward IncrementRateLimit to %s: %w", owner, err)
+	}
+	return resp.Current, nil
+}
+
+// handleCheckRateLimit serves incoming forwarded CheckRateLimit requests (via
+// clusterPeerServiceAdapter), always from the local counter (callers are
+// expected to have confirmed ownership before forwarding).
+func (c *Cluster) handleCheckRateLimit(ctx context.Context, req *PeerRequest) (*PeerResponse, error) {
+	current, err := c.counter.Get(ctx, req.APIKey, req.Window)
+	if err != nil {
+		return nil, err
+	}
+	return &PeerResponse{Current: current}, nil
+}
+
+func (c *Cluster) handleIncrementRateLimit(ctx context.Context, req *PeerRequest) (*PeerResponse, error) {
+	current, err := c.counter.Increment(ctx, req.APIKey, req.Window, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &PeerResponse{Current: current}, nil
+}
+
+// Register registers the inter-peer gRPC service on server.
+func (c *Cluster) Register(server *grpc.Server) {
+	RegisterPeerServiceServer(server, clusterPeerServiceAdapter{c})
+}
+
+// clusterPeerServiceAdapter adapts Cluster's unexported handlers to the
+// peerService interface without exposing them on Cluster's public API.
+type clusterPeerServiceAdapter struct{ c *Cluster }
+
+func (a clusterPeerServiceAdapter) CheckRateLimit(ctx context.Context, req *PeerRequest) (*PeerResponse, error) {
+	return a.c.handleCheckRateLimit(ctx, req)
+}
+
+func (a clusterPeerServiceAdapter) IncrementRateLimit(ctx context.Context, req *PeerRequest) (*PeerResponse, error) {
+	return a.c.handleIncrementRateLimit(ctx, req)
+}
+
+func (c *Cluster) clientFor(peer string) (peerService, error) {
+	c.mu.RLock()
+	client, ok := c.clients[peer]
+	c.mu.RUnlock()
+	if ok {
+		return client, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[peer]; ok {
+		return client, nil
+	}
+
+	conn, err := grpc.NewClient(peer,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(peerCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial peer %s: %w", peer, err)
+	}
+
+	wrapped := &grpcPeerClient{conn: conn}
+	c.clients[peer] = wrapped
+	return wrapped, nil
+}
+
+// grpcPeerClient is a thin peerService client over a gRPC connection.
+type grpcPeerClient struct {
+	conn *grpc.ClientConn
+}
+
+func (g *grpcPeerClient) CheckRateLimit(ctx context.Context, req *PeerRequest) (*PeerResponse, error) {
+	out := new(PeerResponse)
+	if err := g.conn.Invoke(ctx, "/flowguard.cluster.PeerService/CheckRateLimit", req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (g *grpcPeerClient) IncrementRateLimit(ctx context.Context, req *PeerRequest) (*PeerResponse, error) {
+	out := new(PeerResponse)
+	if err := g.conn.Invoke(ctx, "/flowguard.cluster.PeerService/IncrementRateLimit", req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,84 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"rate-limiting-gateway/internal/storage"
+)
+
+// InMemoryCounter is the Counter an owner peer uses for keys it owns: counts
+// live in memory for fast local access, with a periodic copy written to
+// Redis. The counter never reads those copies back: a new counter starts
+// from zero.
+type InMemoryCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+	redis  *storage.RedisClient
+}
+
+// NewInMemoryCounter creates a counter that persists to redis on a fixed
+// interval; pass a nil redis client to disable persistence entirely.
+func NewInMemoryCounter(redis *storage.RedisClient, persistInterval time.Duration) *InMemoryCounter {
+	c := &InMemoryCounter{
+		counts: make(map[string]int),
+		redis:  redis,
+	}
+	if redis != nil && persistInterval > 0 {
+		go c.persistLoop(persistInterval)
+	}
+	return c
+}
+
+func (c *InMemoryCounter) Get(ctx context.Context, key, window string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[key+"|"+window], nil
+}
+
+func (c *InMemoryCounter) Increment(ctx context.Context, key, window string, limit int) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := key + "|" + window
+	c.counts[k]++
+	return c.counts[k], nil
+}
+
+func (c *InMemoryCounter) persistLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.persistAll()
+	}
+}
+
+func (c *InMemoryCounter) persistAll() {
+	c.mu.Lock()
+	snapshot := make(map[string]int, len(c.counts))
+	for k, v := range c.counts {
+		snapshot[k] = v
+	}
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for k, v := range snapshot {
+		apiKey, window := splitCounterKey(k)
+		if apiKey == "" {
+			continue
+		}
+		_ = c.redis.SetRateLimitCount(ctx, apiKey, window, v)
+	}
+}
+
+func splitCounterKey(k string) (apiKey, window string) {
+	for i := len(k) - 1; i >= 0; i-- {
+		if k[i] == '|' {
+			return k[:i], k[i+1:]
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// peerCodec marshals/unmarshals PeerRequest/PeerResponse for the PeerService
+// gRPC connection. Unlike the Envoy RateLimitService (see
+// limiter.envoyProtoCodec), PeerService is purely internal — both ends are
+// flowguard nodes, not a third-party protobuf client — so there's no wire
+// schema to match and encoding/gob is sufficient. It still needs to exist:
+// without it, grpc-go's default "proto" codec would reject these types the
+// same way it rejects the Envoy ones, since neither implements proto.Message.
+type peerCodec struct{}
+
+func (peerCodec) Name() string { return "flowguard-peer" }
+
+func (peerCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, fmt.Errorf("peer: encode %T: %w", v, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (peerCodec) Unmarshal(data []byte, v interface{}) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		return fmt.Errorf("peer: decode %T: %w", v, err)
+	}
+	return nil
+}
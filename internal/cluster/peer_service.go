@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// PeerRequest is forwarded by a non-owner node to the peer that owns a key.
+type PeerRequest struct {
+	APIKey string
+	Window string
+	Limit  int
+}
+
+// PeerResponse carries the owner's counter state back to the forwarding node.
+type PeerResponse struct {
+	Current int
+}
+
+// peerService is the narrow interface the generated peer RPC stubs would
+// normally require. PeerRequest/PeerResponse are hand-rolled stand-ins for
+// generated message types, paired with peerCodec (see peer_codec.go) so the
+// PeerService connection actually works over gRPC.
+type peerService interface {
+	CheckRateLimit(ctx context.Context, req *PeerRequest) (*PeerResponse, error)
+	IncrementRateLimit(ctx context.Context, req *PeerRequest) (*PeerResponse, error)
+}
+
+// GRPCServerOptions returns the grpc.ServerOption(s) the caller must pass to
+// grpc.NewServer before calling Cluster.Register. Without ForceServerCodec,
+// grpc-go's default "proto" codec rejects PeerRequest/PeerResponse outright
+// since neither implements proto.Message.
+func GRPCServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.ForceServerCodec(peerCodec{})}
+}
+
+var peerServiceDesc = grpc.ServiceDesc{
+	ServiceName: "flowguard.cluster.PeerService",
+	HandlerType: (*peerService)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "CheckRateLimit", Handler: peerCheckRateLimitHandler},
+		{MethodName: "IncrementRateLimit", Handler: peerIncrementRateLimitHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "flowguard/cluster/peer.proto",
+}
+
+func peerCheckRateLimitHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(peerService).CheckRateLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/flowguard.cluster.PeerService/CheckRateLimit"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(peerService).CheckRateLimit(ctx, req.(*PeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func peerIncrementRateLimitHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(peerService).IncrementRateLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/flowguard.cluster.PeerService/IncrementRateLimit"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(peerService).IncrementRateLimit(ctx, req.(*PeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RegisterPeerServiceServer registers srv as the implementation of the
+// inter-node PeerService on the given gRPC server.
+func RegisterPeerServiceServer(s *grpc.Server, srv peerService) {
+	s.RegisterService(&peerServiceDesc, srv)
+}
@@ -0,0 +1,109 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Resolver discovers the current set of peer addresses for the cluster.
+// Implementations are pluggable so membership can come from static config,
+// DNS, or a service registry like etcd.
+type Resolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// StaticResolver returns a fixed list of peer addresses from config.
+type StaticResolver struct {
+	Peers []string
+}
+
+// NewStaticResolver creates a resolver backed by a fixed peer list.
+func NewStaticResolver(peers []string) *StaticResolver {
+	return &StaticResolver{Peers: peers}
+}
+
+func (s *StaticResolver) Resolve(ctx context.Context) ([]string, error) {
+	return s.Peers, nil
+}
+
+// DNSResolver discovers peers via a DNS SRV record, falling back to an A/AAAA
+// lookup (with a fixed port) when no SRV record is published.
+type DNSResolver struct {
+	Service  string // e.g. "flowguard-peers"
+	Proto    string // e.g. "tcp"
+	Name     string // DNS domain, e.g. "flowguard.default.svc.cluster.local"
+	Fallback string // host to use for a plain A/AAAA lookup
+	Port     string // port to pair with Fallback
+}
+
+// NewDNSResolver creates a resolver that queries the given SRV service/proto/name.
+func NewDNSResolver(service, proto, name, fallback, port string) *DNSResolver {
+	return &DNSResolver{Service: service, Proto: proto, Name: name, Fallback: fallback, Port: port}
+}
+
+func (d *DNSResolver) Resolve(ctx context.Context) ([]string, error) {
+	_, srvs, err := net.DefaultResolver.LookupSRV(ctx, d.Service, d.Proto, d.Name)
+	if err == nil && len(srvs) > 0 {
+		peers := make([]string, 0, len(srvs))
+		for _, srv := range srvs {
+			peers = append(peers, fmt.Sprintf("%s:%d", trimTrailingDot(srv.Target), srv.Port))
+		}
+		return peers, nil
+	}
+
+	if d.Fallback == "" {
+		return nil, fmt.Errorf("dns resolver: no SRV records for %s and no fallback host configured", d.Name)
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, d.Fallback)
+	if err != nil {
+		return nil, fmt.Errorf("dns resolver: failed to resolve %s: %w", d.Fallback, err)
+	}
+
+	peers := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		peers = append(peers, net.JoinHostPort(addr, d.Port))
+	}
+	return peers, nil
+}
+
+func trimTrailingDot(host string) string {
+	if len(host) > 0 && host[len(host)-1] == '.' {
+		return host[:len(host)-1]
+	}
+	return host
+}
+
+// EtcdResolver discovers peers by listing keys under a prefix in etcd, where
+// each peer registers its own address as a lease-backed key.
+type EtcdResolver struct {
+	client  *clientv3.Client
+	prefix  string
+	timeout time.Duration
+}
+
+// NewEtcdResolver creates a resolver backed by an etcd client, listing peer
+// addresses registered under keyPrefix (e.g. "/flowguard/peers/").
+func NewEtcdResolver(client *clientv3.Client, keyPrefix string) *EtcdResolver {
+	return &EtcdResolver{client: client, prefix: keyPrefix, timeout: 5 * time.Second}
+}
+
+func (e *EtcdResolver) Resolve(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	resp, err := e.client.Get(ctx, e.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("etcd resolver: failed to list %s: %w", e.prefix, err)
+	}
+
+	peers := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		peers = append(peers, string(kv.Value))
+	}
+	return peers, nil
+}
@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// vnodesPerPeer is the number of virtual nodes placed on the ring for each
+// peer, smoothing out key distribution as peers join and leave.
+const vnodesPerPeer = 160
+
+// HashRing consistently maps rate-limit keys to a single owning peer, so a
+// given key is always handled (and counted) by the same node absent
+// membership changes.
+type HashRing struct {
+	mu      sync.RWMutex
+	sorted  []uint32
+	vnodes  map[uint32]string
+	members map[string]bool
+}
+
+// NewHashRing creates an empty consistent hash ring.
+func NewHashRing() *HashRing {
+	return &HashRing{
+		vnodes:  make(map[uint32]string),
+		members: make(map[string]bool),
+	}
+}
+
+// SetPeers replaces the ring's membership with exactly the given peer
+// addresses, used whenever the resolver reports a new view of the cluster.
+func (h *HashRing) SetPeers(peers []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.vnodes = make(map[uint32]string, len(peers)*vnodesPerPeer)
+	h.members = make(map[string]bool, len(peers))
+	h.sorted = h.sorted[:0]
+
+	for _, peer := range peers {
+		h.members[peer] = true
+		for i := 0; i < vnodesPerPeer; i++ {
+			hash := hashKey(peer + "#" + strconv.Itoa(i))
+			h.vnodes[hash] = peer
+			h.sorted = append(h.sorted, hash)
+		}
+	}
+
+	sort.Slice(h.sorted, func(i, j int) bool { return h.sorted[i] < h.sorted[j] })
+}
+
+// Owner returns the peer address that owns the given rate-limit key.
+func (h *HashRing) Owner(key string) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.sorted) == 0 {
+		return "", false
+	}
+
+	hash := hashKey(key)
+	idx := sort.Search(len(h.sorted), func(i int) bool { return h.sorted[i] >= hash })
+	if idx == len(h.sorted) {
+		idx = 0
+	}
+
+	return h.vnodes[h.sorted[idx]], true
+}
+
+// HasPeer reports whether a peer address is currently a ring member.
+func (h *HashRing) HasPeer(peer string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.members[peer]
+}
+
+func hashKey(key string) uint32 {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(key))
+	return hasher.Sum32()
+}
@@ -0,0 +1,41 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRing_OwnerIsStableAcrossCalls(t *testing.T) {
+	ring := NewHashRing()
+	ring.SetPeers([]string{"peer-a:9000", "peer-b:9000", "peer-c:9000"})
+
+	owner, ok := ring.Owner("rate:some-api-key:2026-07-26-10-00")
+	assert.True(t, ok)
+	assert.True(t, ring.HasPeer(owner))
+
+	for i := 0; i < 10; i++ {
+		repeat, _ := ring.Owner("rate:some-api-key:2026-07-26-10-00")
+		assert.Equal(t, owner, repeat)
+	}
+}
+
+func TestHashRing_NoPeersReturnsFalse(t *testing.T) {
+	ring := NewHashRing()
+	_, ok := ring.Owner("rate:some-api-key:2026-07-26-10-00")
+	assert.False(t, ok)
+}
+
+func TestHashRing_DistributesKeysAcrossPeers(t *testing.T) {
+	ring := NewHashRing()
+	peers := []string{"peer-a:9000", "peer-b:9000", "peer-c:9000"}
+	ring.SetPeers(peers)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		owner, _ := ring.Owner(assert.AnError.Error() + string(rune(i)))
+		seen[owner] = true
+	}
+
+	assert.Greater(t, len(seen), 1, "expected keys to spread across more than one peer")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,11 +12,102 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Redis    RedisConfig
-	RateLimit RateLimitConfig
-	Target   TargetConfig
-	Logging  LoggingConfig
+	Server      ServerConfig
+	Redis       RedisConfig
+	RateLimit   RateLimitConfig
+	Target      TargetConfig
+	Logging     LoggingConfig
+	GRPC        GRPCConfig
+	Cluster     ClusterConfig
+	KeyStore    KeyStoreConfig
+	Upstream    UpstreamConfig
+	Concurrency ConcurrencyConfig
+	Admin       AdminConfig
+}
+
+// AdminConfig gates the /admin/* route groups (client CRUD, bypass set,
+// backend circuit-breaker overrides). These routes can mint unlimited
+// clients, disable rate limiting for a key, or force a backend's circuit
+// open, so they're deliberately never covered by AuthMiddleware.Authenticate
+// — a leaked/guessed regular API key must not grant admin access.
+type AdminConfig struct {
+	// Token is the shared secret required in the X-Admin-Token header on
+	// every /admin/* request. Empty disables all /admin/* routes entirely
+	// (they 404) rather than leaving them reachable with no credential.
+	Token string
+	// Port, if set, serves /admin/* on its own http.Server bound to this
+	// port instead of the public gateway port, so it can be placed behind
+	// an internal-only listener/network ACL. Empty serves /admin/* on the
+	// public port, still gated by Token.
+	Port string
+}
+
+// ConcurrencyConfig bounds the number of simultaneously in-flight requests
+// (see middleware.ConcurrencyMiddleware), independent of the request-rate
+// windows enforced by RateLimitConfig.
+type ConcurrencyConfig struct {
+	// GlobalMaxInFlight caps in-flight requests across the whole process.
+	// Zero disables the global cap.
+	GlobalMaxInFlight int
+	// PerKeyMaxInFlight caps in-flight requests per API key. Zero disables
+	// the per-key cap.
+	PerKeyMaxInFlight int
+	// MaxWait is how long a request blocks waiting for a slot before
+	// failing fast. Zero fails fast immediately instead of waiting.
+	MaxWait time.Duration
+	// LongRunningPatterns are regexes matched against the request path;
+	// matching requests are admitted into a separate pool sized by
+	// LongRunningMaxInFlight so streaming/upload routes can't starve short
+	// requests out of the global pool.
+	LongRunningPatterns []string
+	// LongRunningMaxInFlight caps in-flight requests admitted into the
+	// long-running pool. Zero disables the separate pool, leaving matching
+	// requests on the global/per-key caps only.
+	LongRunningMaxInFlight int
+	// PerKeyCacheCapacity bounds how many distinct per-API-key semaphores
+	// middleware.ConcurrencyMiddleware holds at once, evicting the least
+	// recently used once full so an attacker flooding random API keys can't
+	// grow this map unbounded. Zero falls back to a sane default.
+	PerKeyCacheCapacity int
+}
+
+// KeyStoreConfig selects and configures the pluggable API-key backend. When
+// neither backend is configured, the gateway falls back to its original
+// any-non-empty-string validation against Redis.
+type KeyStoreConfig struct {
+	// Backend is "postgres", "redis", or "" (disabled).
+	Backend string
+	// PostgresDSN is the connection string used when Backend is "postgres".
+	PostgresDSN string
+	// CacheSize and CacheTTL bound the LRU+TTL cache placed in front of the
+	// backend so a hot key doesn't hit it on every request.
+	CacheSize int
+	CacheTTL  time.Duration
+}
+
+// ClusterConfig holds configuration for the peer-based distributed rate
+// limiting subsystem.
+type ClusterConfig struct {
+	// Enabled turns on peer-based limiting; when false every key is handled
+	// locally against Redis as before.
+	Enabled bool
+	// SelfAddr is this node's own address, as dialed by other peers.
+	SelfAddr string
+	// PeerPort is the port the inter-node PeerService gRPC server listens on.
+	PeerPort string
+	// Peers is the static peer list used when no DNS/etcd resolver is configured.
+	Peers []string
+	// GossipInterval controls how often membership is re-resolved and peers are health-checked.
+	GossipInterval time.Duration
+}
+
+// GRPCConfig holds configuration for the Envoy-compatible gRPC RateLimitService
+type GRPCConfig struct {
+	// Port the RateLimitService listens on. Empty disables the gRPC server.
+	Port string
+	// DescriptorConfigPath points at the YAML file describing hierarchical
+	// descriptor rate limit rules (see limiter.LoadDescriptorConfig).
+	DescriptorConfigPath string
 }
 
 // ServerConfig holds HTTP server configuration
@@ -41,12 +133,76 @@ type RateLimitConfig struct {
 	RequestsPerDay    int
 	MonthlyQuota      int
 	WindowSize        time.Duration
+
+	// FrontendRequestsPerSecond caps unauthenticated/pre-auth traffic keyed
+	// by client IP (and JSON-RPC method, for application/json bodies),
+	// independent of the per-API-key limits above.
+	FrontendRequestsPerSecond int
+	// ExemptUserAgents skips the frontend limiter (but not the API-key
+	// limiter) for requests whose User-Agent header matches one of these
+	// values exactly.
+	ExemptUserAgents []string
+	// ExemptUserAgentPatterns skips the frontend limiter for requests whose
+	// User-Agent header matches one of these regexes.
+	ExemptUserAgentPatterns []string
+	// ExemptOrigins skips the frontend limiter for requests whose Origin
+	// header matches one of these values exactly.
+	ExemptOrigins []string
+	// ExemptCIDRs skips the frontend limiter for requests whose client IP
+	// falls in one of these ranges (e.g. an internal health-check prober).
+	ExemptCIDRs []string
+	// TrustedProxyCIDRs are the ranges the gateway's own X-Forwarded-For is
+	// trusted from; the frontend limiter only honors that header when
+	// Gin's RemoteIP falls in one of these, and otherwise keys on RemoteIP
+	// directly so a client can't spoof its way into someone else's bucket.
+	TrustedProxyCIDRs []string
+	// PerMethodLimits overrides FrontendRequestsPerSecond for specific
+	// JSON-RPC method names, e.g. {"eth_call": 5}.
+	PerMethodLimits map[string]int
+	// FrontendBucketCapacity bounds how many distinct token-bucket entries
+	// the frontend limiter holds at once, evicting the least recently used
+	// once full so memory can't grow unbounded under an IP-spoofing flood.
+	FrontendBucketCapacity int
+
+	// PolicyConfigPath points at the YAML or JSON file describing
+	// per-route/per-API policy partitions (see limiter.LoadPolicyConfig).
+	// Empty disables the policy subsystem, leaving every API key on the
+	// global limits above.
+	PolicyConfigPath string
+	// PolicyReloadInterval, if positive, re-reads PolicyConfigPath on this
+	// interval so policy/assignment changes take effect without a restart
+	// (see limiter.WatchPolicyConfig). Zero disables hot-reload.
+	PolicyReloadInterval time.Duration
+
+	// BypassKeys are API keys that skip rate limiting entirely (see
+	// limiter.RateLimiter.isBypassed). Combined at runtime with the dynamic
+	// Redis bypass set managed via POST/DELETE /admin/bypass.
+	BypassKeys []string
+	// BypassCIDRs are client IP ranges (e.g. "10.0.0.0/8") that skip rate
+	// limiting entirely, for internal callers that must never be throttled.
+	BypassCIDRs []string
 }
 
 // TargetConfig holds upstream service configuration
 type TargetConfig struct {
 	URL     string
 	Timeout time.Duration
+	// FlushInterval controls how often the reverse proxy flushes buffered
+	// response bytes to the client. 0 uses httputil.ReverseProxy's default
+	// (which already flushes immediately for text/event-stream responses);
+	// a small positive value helps long-polling responses that don't set
+	// that content type stream promptly instead of batching.
+	FlushInterval time.Duration
+}
+
+// UpstreamConfig configures the optional per-route upstream pool backing
+// GatewayHandler's proxy, in place of a single static Target.URL.
+type UpstreamConfig struct {
+	// RouteConfigPath points at a YAML file of path-prefix routes, each with
+	// its own backend pool, load-balancing strategy, and health check policy
+	// (see upstream.LoadRouteConfig). Empty disables route-table proxying,
+	// falling back to the single static Target.URL for every path.
+	RouteConfigPath string
 }
 
 // LoggingConfig holds logging configuration
@@ -76,20 +232,64 @@ func Load() (*Config, error) {
 			PoolSize: getEnvAsInt("REDIS_POOL_SIZE", 10),
 		},
 		RateLimit: RateLimitConfig{
-			RequestsPerMinute: getEnvAsInt("RATE_LIMIT_PER_MINUTE", 60),
-			RequestsPerHour:   getEnvAsInt("RATE_LIMIT_PER_HOUR", 1000),
-			RequestsPerDay:    getEnvAsInt("RATE_LIMIT_PER_DAY", 10000),
-			MonthlyQuota:      getEnvAsInt("RATE_LIMIT_MONTHLY_QUOTA", 100000),
-			WindowSize:        getEnvAsDuration("RATE_LIMIT_WINDOW_SIZE", time.Minute),
+			RequestsPerMinute:         getEnvAsInt("RATE_LIMIT_PER_MINUTE", 60),
+			RequestsPerHour:           getEnvAsInt("RATE_LIMIT_PER_HOUR", 1000),
+			RequestsPerDay:            getEnvAsInt("RATE_LIMIT_PER_DAY", 10000),
+			MonthlyQuota:              getEnvAsInt("RATE_LIMIT_MONTHLY_QUOTA", 100000),
+			WindowSize:                getEnvAsDuration("RATE_LIMIT_WINDOW_SIZE", time.Minute),
+			FrontendRequestsPerSecond: getEnvAsInt("FRONTEND_RATE_LIMIT_PER_SECOND", 10),
+			ExemptUserAgents:          getEnvAsStringSlice("FRONTEND_EXEMPT_USER_AGENTS"),
+			ExemptUserAgentPatterns:   getEnvAsStringSlice("FRONTEND_EXEMPT_USER_AGENT_PATTERNS"),
+			ExemptOrigins:             getEnvAsStringSlice("FRONTEND_EXEMPT_ORIGINS"),
+			ExemptCIDRs:               getEnvAsStringSlice("FRONTEND_EXEMPT_CIDRS"),
+			TrustedProxyCIDRs:         getEnvAsStringSlice("FRONTEND_TRUSTED_PROXY_CIDRS"),
+			FrontendBucketCapacity:    getEnvAsInt("FRONTEND_BUCKET_CAPACITY", 65536),
+			PolicyConfigPath:          getEnv("RATE_LIMIT_POLICY_CONFIG", ""),
+			PolicyReloadInterval:      getEnvAsDuration("RATE_LIMIT_POLICY_RELOAD_INTERVAL", 0),
+			BypassKeys:                getEnvAsStringSlice("RATE_LIMIT_BYPASS_KEYS"),
+			BypassCIDRs:               getEnvAsStringSlice("RATE_LIMIT_BYPASS_CIDRS"),
 		},
 		Target: TargetConfig{
-			URL:     getEnv("TARGET_URL", "http://localhost:3000"),
-			Timeout: getEnvAsDuration("TARGET_TIMEOUT", 30*time.Second),
+			URL:           getEnv("TARGET_URL", "http://localhost:3000"),
+			Timeout:       getEnvAsDuration("TARGET_TIMEOUT", 30*time.Second),
+			FlushInterval: getEnvAsDuration("TARGET_FLUSH_INTERVAL", 0),
 		},
 		Logging: LoggingConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
 			JSON:  getEnvAsBool("LOG_JSON", true),
 		},
+		GRPC: GRPCConfig{
+			Port:                 getEnv("GRPC_PORT", ""),
+			DescriptorConfigPath: getEnv("GRPC_DESCRIPTOR_CONFIG", ""),
+		},
+		Cluster: ClusterConfig{
+			Enabled:        getEnvAsBool("CLUSTER_ENABLED", false),
+			SelfAddr:       getEnv("CLUSTER_SELF_ADDR", ""),
+			PeerPort:       getEnv("CLUSTER_PEER_PORT", "7070"),
+			Peers:          getEnvAsStringSlice("CLUSTER_PEERS"),
+			GossipInterval: getEnvAsDuration("CLUSTER_GOSSIP_INTERVAL", 5*time.Second),
+		},
+		KeyStore: KeyStoreConfig{
+			Backend:     getEnv("KEYSTORE_BACKEND", ""),
+			PostgresDSN: getEnv("KEYSTORE_POSTGRES_DSN", ""),
+			CacheSize:   getEnvAsInt("KEYSTORE_CACHE_SIZE", 10000),
+			CacheTTL:    getEnvAsDuration("KEYSTORE_CACHE_TTL", time.Minute),
+		},
+		Upstream: UpstreamConfig{
+			RouteConfigPath: getEnv("UPSTREAM_ROUTE_CONFIG", ""),
+		},
+		Concurrency: ConcurrencyConfig{
+			GlobalMaxInFlight:      getEnvAsInt("CONCURRENCY_GLOBAL_MAX_IN_FLIGHT", 0),
+			PerKeyMaxInFlight:      getEnvAsInt("CONCURRENCY_PER_KEY_MAX_IN_FLIGHT", 0),
+			MaxWait:                getEnvAsDuration("CONCURRENCY_MAX_WAIT", 0),
+			LongRunningPatterns:    getEnvAsStringSlice("CONCURRENCY_LONG_RUNNING_PATTERNS"),
+			LongRunningMaxInFlight: getEnvAsInt("CONCURRENCY_LONG_RUNNING_MAX_IN_FLIGHT", 0),
+			PerKeyCacheCapacity:    getEnvAsInt("CONCURRENCY_PER_KEY_CACHE_CAPACITY", 65536),
+		},
+		Admin: AdminConfig{
+			Token: getEnv("ADMIN_AUTH_TOKEN", ""),
+			Port:  getEnv("ADMIN_PORT", ""),
+		},
 	}
 
 	return config, nil
@@ -133,6 +333,23 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvAsStringSlice gets an environment variable as a comma-separated list
+func getEnvAsStringSlice(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	if c.Server.Port == "" {
@@ -145,4 +362,4 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("target URL is required")
 	}
 	return nil
-} 
\ No newline at end of file
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"rate-limiting-gateway/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler exposes CRUD operations over the KeyStore backing pluggable
+// API-key tiers, for operators managing clients out-of-band from whatever
+// system of record the KeyStore is pointed at.
+type AdminHandler struct {
+	keys   storage.KeyStore
+	logger *slog.Logger
+}
+
+// NewAdminHandler creates a new admin handler over keys.
+func NewAdminHandler(keys storage.KeyStore, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// clientRequest is the JSON body accepted by UpsertClient.
+type clientRequest struct {
+	APIKey            string   `json:"api_key"`
+	Tier              string   `json:"tier"`
+	RequestsPerMinute int      `json:"requests_per_minute"`
+	RequestsPerHour   int      `json:"requests_per_hour"`
+	RequestsPerDay    int      `json:"requests_per_day"`
+	MonthlyQuota      int      `json:"monthly_quota"`
+	AllowedAPIs       []string `json:"allowed_apis"`
+	Disabled          bool     `json:"disabled"`
+	Unlimited         bool     `json:"unlimited"`
+}
+
+// GetClient returns the client record for the api_key path parameter.
+func (a *AdminHandler) GetClient(c *gin.Context) {
+	apiKey := c.Param("api_key")
+
+	info, err := a.keys.Lookup(c.Request.Context(), apiKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrClientNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Client not found",
+				"code":  "CLIENT_NOT_FOUND",
+			})
+			return
+		}
+
+		a.logger.Error("Failed to look up client",
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.Any("error", err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Internal server error",
+			"code":  "ADMIN_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// UpsertClient creates or replaces a client record from the request body.
+// When called via PUT /admin/clients/:api_key, the path parameter takes
+// precedence over any api_key in the body.
+func (a *AdminHandler) UpsertClient(c *gin.Context) {
+	var req clientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+			"code":  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if pathKey := c.Param("api_key"); pathKey != "" {
+		req.APIKey = pathKey
+	}
+	if req.APIKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "api_key is required",
+			"code":  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	info := &storage.ClientInfo{
+		APIKey:            req.APIKey,
+		Tier:              req.Tier,
+		RequestsPerMinute: req.RequestsPerMinute,
+		RequestsPerHour:   req.RequestsPerHour,
+		RequestsPerDay:    req.RequestsPerDay,
+		MonthlyQuota:      req.MonthlyQuota,
+		AllowedAPIs:       req.AllowedAPIs,
+		Disabled:          req.Disabled,
+		Unlimited:         req.Unlimited,
+	}
+
+	if err := a.keys.Upsert(c.Request.Context(), info); err != nil {
+		a.logger.Error("Failed to upsert client",
+			slog.String("api_key", maskAPIKey(req.APIKey)),
+			slog.Any("error", err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save client",
+			"code":  "ADMIN_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// DeleteClient removes the client record for the api_key path parameter.
+func (a *AdminHandler) DeleteClient(c *gin.Context) {
+	apiKey := c.Param("api_key")
+
+	if err := a.keys.Delete(c.Request.Context(), apiKey); err != nil {
+		a.logger.Error("Failed to delete client",
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.Any("error", err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete client",
+			"code":  "ADMIN_ERROR",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
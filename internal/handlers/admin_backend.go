@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"rate-limiting-gateway/internal/limiter"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminBackendHandler exposes inspection and manual override of per-backend
+// circuit breaker state for /admin/backends.
+type AdminBackendHandler struct {
+	backends *limiter.BackendLimiter
+	logger   *slog.Logger
+}
+
+// NewAdminBackendHandler creates a new admin backend handler over backends.
+func NewAdminBackendHandler(backends *limiter.BackendLimiter, logger *slog.Logger) *AdminBackendHandler {
+	return &AdminBackendHandler{
+		backends: backends,
+		logger:   logger,
+	}
+}
+
+// GetBackend reports the circuit breaker state and counters for the
+// :backend path parameter.
+func (a *AdminBackendHandler) GetBackend(c *gin.Context) {
+	backend := c.Param("backend")
+
+	state, err := a.backends.GetBackendState(c.Request.Context(), backend)
+	if err != nil {
+		a.logger.Error("Failed to get backend state",
+			slog.String("backend", backend),
+			slog.Any("error", err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get backend state",
+			"code":  "ADMIN_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// backendOverrideRequest is the JSON body accepted by SetBackendOverride.
+type backendOverrideRequest struct {
+	Action string `json:"action"` // "open" or "close"
+}
+
+// SetBackendOverride force-opens or force-closes the :backend path
+// parameter's circuit breaker, for an operator overriding the automatic
+// health tracking.
+func (a *AdminBackendHandler) SetBackendOverride(c *gin.Context) {
+	backend := c.Param("backend")
+
+	var req backendOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+			"code":  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	switch req.Action {
+	case "open":
+		a.backends.ForceOpen(backend)
+	case "close":
+		a.backends.ForceClose(backend)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": `action must be "open" or "close"`,
+			"code":  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	state, err := a.backends.GetBackendState(c.Request.Context(), backend)
+	if err != nil {
+		a.logger.Error("Failed to get backend state after override",
+			slog.String("backend", backend),
+			slog.Any("error", err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get backend state",
+			"code":  "ADMIN_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
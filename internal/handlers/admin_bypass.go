@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"rate-limiting-gateway/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// BypassHandler exposes CRUD operations over the dynamic Redis bypass-key
+// set (see storage.RedisClient.AddBypassKey), for operators who need to
+// exempt a client from rate limiting at runtime without a redeploy.
+type BypassHandler struct {
+	redis  *storage.RedisClient
+	logger *slog.Logger
+}
+
+// NewBypassHandler creates a new bypass handler over redis.
+func NewBypassHandler(redis *storage.RedisClient, logger *slog.Logger) *BypassHandler {
+	return &BypassHandler{
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// bypassKeyRequest is the JSON body accepted by AddBypassKey.
+type bypassKeyRequest struct {
+	APIKey string `json:"api_key"`
+}
+
+// AddBypassKey adds the api_key from the request body to the dynamic bypass set.
+func (b *BypassHandler) AddBypassKey(c *gin.Context) {
+	var req bypassKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.APIKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "api_key is required",
+			"code":  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := b.redis.AddBypassKey(c.Request.Context(), req.APIKey); err != nil {
+		b.logger.Error("Failed to add bypass key",
+			slog.String("api_key", maskAPIKey(req.APIKey)),
+			slog.Any("error", err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to add bypass key",
+			"code":  "ADMIN_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_key": req.APIKey, "bypass": true})
+}
+
+// RemoveBypassKey removes the api_key path parameter from the dynamic bypass set.
+func (b *BypassHandler) RemoveBypassKey(c *gin.Context) {
+	apiKey := c.Param("api_key")
+
+	if err := b.redis.RemoveBypassKey(c.Request.Context(), apiKey); err != nil {
+		b.logger.Error("Failed to remove bypass key",
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.Any("error", err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove bypass key",
+			"code":  "ADMIN_ERROR",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
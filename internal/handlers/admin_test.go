@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rate-limiting-gateway/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKeyStore is an in-memory storage.KeyStore for exercising AdminHandler's
+// CRUD routes without a real Postgres/Redis backend.
+type fakeKeyStore struct {
+	clients map[string]*storage.ClientInfo
+}
+
+func newFakeKeyStore() *fakeKeyStore {
+	return &fakeKeyStore{clients: make(map[string]*storage.ClientInfo)}
+}
+
+func (f *fakeKeyStore) Lookup(ctx context.Context, apiKey string) (*storage.ClientInfo, error) {
+	info, ok := f.clients[apiKey]
+	if !ok {
+		return nil, storage.ErrClientNotFound
+	}
+	return info, nil
+}
+
+func (f *fakeKeyStore) Upsert(ctx context.Context, info *storage.ClientInfo) error {
+	f.clients[info.APIKey] = info
+	return nil
+}
+
+func (f *fakeKeyStore) Delete(ctx context.Context, apiKey string) error {
+	delete(f.clients, apiKey)
+	return nil
+}
+
+func newAdminTestRouter(keys storage.KeyStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewAdminHandler(keys, slog.Default())
+
+	router := gin.New()
+	router.GET("/admin/clients/:api_key", handler.GetClient)
+	router.PUT("/admin/clients/:api_key", handler.UpsertClient)
+	router.DELETE("/admin/clients/:api_key", handler.DeleteClient)
+	return router
+}
+
+func TestAdminHandler_UpsertThenGetClient(t *testing.T) {
+	router := newAdminTestRouter(newFakeKeyStore())
+
+	body := `{"tier":"gold","requests_per_minute":120,"monthly_quota":100000}`
+	req, _ := http.NewRequest("PUT", "/admin/clients/test-key", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/admin/clients/test-key", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var info storage.ClientInfo
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Equal(t, "test-key", info.APIKey)
+	assert.Equal(t, "gold", info.Tier)
+	assert.Equal(t, 120, info.RequestsPerMinute)
+}
+
+func TestAdminHandler_UpsertClient_PathKeyOverridesBodyKey(t *testing.T) {
+	keys := newFakeKeyStore()
+	router := newAdminTestRouter(keys)
+
+	body := `{"api_key":"body-key","tier":"silver"}`
+	req, _ := http.NewRequest("PUT", "/admin/clients/path-key", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, err := keys.Lookup(context.Background(), "path-key")
+	assert.NoError(t, err, "record should be stored under the path parameter's key")
+
+	_, err = keys.Lookup(context.Background(), "body-key")
+	assert.ErrorIs(t, err, storage.ErrClientNotFound, "the body's api_key must not override the path parameter")
+}
+
+func TestAdminHandler_GetClient_NotFound(t *testing.T) {
+	router := newAdminTestRouter(newFakeKeyStore())
+
+	req, _ := http.NewRequest("GET", "/admin/clients/missing-key", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "CLIENT_NOT_FOUND", response["code"])
+}
+
+func TestAdminHandler_DeleteClient_RemovesRecord(t *testing.T) {
+	keys := newFakeKeyStore()
+	keys.clients["doomed-key"] = &storage.ClientInfo{APIKey: "doomed-key"}
+	router := newAdminTestRouter(keys)
+
+	req, _ := http.NewRequest("DELETE", "/admin/clients/doomed-key", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	_, err := keys.Lookup(context.Background(), "doomed-key")
+	assert.ErrorIs(t, err, storage.ErrClientNotFound)
+}
@@ -1,45 +1,63 @@
 package handlers
 
 import (
-	"io"
+	"log/slog"
 	"net/http"
+	"net/http/httputil"
 	"time"
 
 	"rate-limiting-gateway/internal/config"
 	"rate-limiting-gateway/internal/limiter"
+	"rate-limiting-gateway/internal/proxy"
+	"rate-limiting-gateway/internal/upstream"
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
 // GatewayHandler handles proxy requests to upstream services
 type GatewayHandler struct {
-	config     *config.Config
-	rateLimiter limiter.RateLimiterInterface
-	logger     *zap.Logger
-	httpClient *http.Client
+	config       *config.Config
+	rateLimiter  limiter.RateLimiterInterface
+	logger       *slog.Logger
+	reverseProxy *httputil.ReverseProxy
+	router       *upstream.Router
+}
+
+// SetRouter wires in an optional per-route upstream pool (see
+// upstream.LoadRouteConfig). Any path matching one of its routes is proxied
+// through that route's load-balanced, health-checked, circuit-broken pool
+// instead of the single static Target.URL.
+func (g *GatewayHandler) SetRouter(router *upstream.Router) {
+	g.router = router
 }
 
 // NewGatewayHandler creates a new gateway handler
-func NewGatewayHandler(config *config.Config, rateLimiter limiter.RateLimiterInterface, logger *zap.Logger) *GatewayHandler {
+func NewGatewayHandler(config *config.Config, rateLimiter limiter.RateLimiterInterface, logger *slog.Logger) *GatewayHandler {
+	reverseProxy, err := proxy.NewReverseProxy(proxy.Config{
+		TargetURL:     config.Target.URL,
+		FlushInterval: config.Target.FlushInterval,
+	}, logger)
+	if err != nil {
+		logger.Error("Failed to build reverse proxy for target",
+			slog.String("target_url", config.Target.URL),
+			slog.Any("error", err),
+		)
+	}
+
 	return &GatewayHandler{
-		config:      config,
-		rateLimiter: rateLimiter,
-		logger:      logger,
-		httpClient: &http.Client{
-			Timeout: config.Target.Timeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 100,
-				IdleConnTimeout:     90 * time.Second,
-			},
-		},
+		config:       config,
+		rateLimiter:  rateLimiter,
+		logger:       logger,
+		reverseProxy: reverseProxy,
 	}
 }
 
-// Proxy forwards requests to the upstream service
+// Proxy forwards requests to the upstream service. Request and response
+// bodies are streamed rather than buffered in memory, so large downloads and
+// chunked/SSE responses work correctly; websocket upgrades are hijacked and
+// proxied separately since httputil.ReverseProxy can't carry those itself.
 func (g *GatewayHandler) Proxy(c *gin.Context) {
 	start := time.Now()
-	
+
 	// Get API key from context
 	apiKey := c.GetString("api_key")
 	if apiKey == "" {
@@ -50,99 +68,98 @@ func (g *GatewayHandler) Proxy(c *gin.Context) {
 		return
 	}
 
-	// Create the target URL
-	targetURL := g.config.Target.URL + c.Request.URL.Path
-	if c.Request.URL.RawQuery != "" {
-		targetURL += "?" + c.Request.URL.RawQuery
+	// Add gateway-specific headers before forwarding
+	c.Request.Header.Set("X-Gateway-API-Key", maskAPIKey(apiKey))
+	c.Request.Header.Set("X-Gateway-Request-ID", c.GetString("request_id"))
+	c.Request.Header.Set("X-Gateway-Timestamp", time.Now().Format(time.RFC3339))
+
+	if g.router != nil {
+		if route, ok := g.router.Match(c.Request.URL.Path); ok {
+			g.proxyRoute(c, route, apiKey, start)
+			return
+		}
 	}
 
-	// Create the request to the upstream service
-	req, err := http.NewRequestWithContext(
-		c.Request.Context(),
-		c.Request.Method,
-		targetURL,
-		c.Request.Body,
-	)
-	if err != nil {
-		g.logger.Error("Failed to create upstream request",
-			zap.String("api_key", maskAPIKey(apiKey)),
-			zap.String("target_url", targetURL),
-			zap.Error(err),
+	if g.reverseProxy == nil {
+		g.logger.Error("Reverse proxy is not configured",
+			slog.String("target_url", g.config.Target.URL),
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create upstream request",
+			"error": "Upstream target is not configured correctly",
 			"code":  "UPSTREAM_ERROR",
 		})
 		return
 	}
 
-	// Copy headers from the original request
-	for key, values := range c.Request.Header {
-		for _, value := range values {
-			req.Header.Add(key, value)
+	if proxy.IsWebsocketUpgrade(c.Request) {
+		proxy.ServeWebsocket(c.Writer, c.Request, g.config.Target.URL, g.logger)
+		if g.logger.Enabled(c.Request.Context(), slog.LevelInfo) {
+			g.logger.Info("Websocket proxied",
+				slog.String("api_key", maskAPIKey(apiKey)),
+				slog.String("path", c.Request.URL.Path),
+				slog.Duration("duration", time.Since(start)),
+			)
 		}
+		return
 	}
 
-	// Add gateway-specific headers
-	req.Header.Set("X-Gateway-API-Key", maskAPIKey(apiKey))
-	req.Header.Set("X-Gateway-Request-ID", c.GetString("request_id"))
-	req.Header.Set("X-Gateway-Timestamp", time.Now().Format(time.RFC3339))
+	g.reverseProxy.ServeHTTP(c.Writer, c.Request)
 
-	// Make the request to the upstream service
-	resp, err := g.httpClient.Do(req)
-	if err != nil {
-		g.logger.Error("Failed to make upstream request",
-			zap.String("api_key", maskAPIKey(apiKey)),
-			zap.String("target_url", targetURL),
-			zap.String("method", c.Request.Method),
-			zap.Error(err),
+	if g.logger.Enabled(c.Request.Context(), slog.LevelInfo) {
+		g.logger.Info("Request proxied successfully",
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("method", c.Request.Method),
+			slog.Int("upstream_status", c.Writer.Status()),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("response_size", c.Writer.Size()),
 		)
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": "Upstream service unavailable",
-			"code":  "UPSTREAM_UNAVAILABLE",
-		})
-		return
 	}
-	defer resp.Body.Close()
+}
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		g.logger.Error("Failed to read upstream response",
-			zap.String("api_key", maskAPIKey(apiKey)),
-			zap.String("target_url", targetURL),
-			zap.Error(err),
-		)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to read upstream response",
-			"code":  "UPSTREAM_ERROR",
-		})
-		return
-	}
+// proxyRoute forwards a request matched to one of the router's path-prefix
+// routes, picking a backend from that route's pool instead of the single
+// static Target.URL.
+func (g *GatewayHandler) proxyRoute(c *gin.Context, route *upstream.Route, apiKey string, start time.Time) {
+	if proxy.IsWebsocketUpgrade(c.Request) {
+		backend, err := route.Pool.Pick(nil)
+		if err != nil {
+			g.logger.Error("No healthy backend for websocket route",
+				slog.String("route", route.Pattern),
+				slog.Any("error", err),
+			)
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Upstream service unavailable",
+				"code":  "UPSTREAM_UNAVAILABLE",
+			})
+			return
+		}
 
-	// Copy response headers
-	for key, values := range resp.Header {
-		for _, value := range values {
-			c.Header(key, value)
+		proxy.ServeWebsocket(c.Writer, c.Request, backend.URL, g.logger)
+		if g.logger.Enabled(c.Request.Context(), slog.LevelInfo) {
+			g.logger.Info("Websocket proxied via route pool",
+				slog.String("api_key", maskAPIKey(apiKey)),
+				slog.String("route", route.Pattern),
+				slog.String("backend", backend.URL),
+				slog.Duration("duration", time.Since(start)),
+			)
 		}
+		return
 	}
 
-	// Add gateway-specific response headers
-	duration := time.Since(start)
-	c.Header("X-Gateway-Response-Time", duration.String())
-	c.Header("X-Gateway-Upstream-Status", resp.Status)
-
-	// Set the response status and body
-	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), body)
-
-	g.logger.Info("Request proxied successfully",
-		zap.String("api_key", maskAPIKey(apiKey)),
-		zap.String("target_url", targetURL),
-		zap.String("method", c.Request.Method),
-		zap.Int("upstream_status", resp.StatusCode),
-		zap.Duration("duration", duration),
-		zap.Int("response_size", len(body)),
-	)
+	route.ReverseProxy.ServeHTTP(c.Writer, c.Request)
+
+	if g.logger.Enabled(c.Request.Context(), slog.LevelInfo) {
+		g.logger.Info("Request proxied via route pool",
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.String("route", route.Pattern),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("method", c.Request.Method),
+			slog.Int("upstream_status", c.Writer.Status()),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("response_size", c.Writer.Size()),
+		)
+	}
 }
 
 // HealthCheck handles health check requests
@@ -169,8 +186,8 @@ func (g *GatewayHandler) GetRateLimitInfo(c *gin.Context) {
 	info, err := g.rateLimiter.GetRateLimitInfo(c.Request.Context(), apiKey)
 	if err != nil {
 		g.logger.Error("Failed to get rate limit info",
-			zap.String("api_key", maskAPIKey(apiKey)),
-			zap.Error(err),
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.Any("error", err),
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get rate limit information",
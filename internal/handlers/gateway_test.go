@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,7 +14,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"go.uber.org/zap"
 )
 
 // MockRateLimiter is a mock implementation of the rate limiter
@@ -21,16 +21,11 @@ type MockRateLimiter struct {
 	mock.Mock
 }
 
-func (m *MockRateLimiter) CheckRateLimit(ctx context.Context, apiKey string) (*limiter.RateLimitResult, error) {
-	args := m.Called(ctx, apiKey)
+func (m *MockRateLimiter) CheckRateLimit(ctx context.Context, apiKey, path, clientIP string) (*limiter.RateLimitResult, error) {
+	args := m.Called(ctx, apiKey, path, clientIP)
 	return args.Get(0).(*limiter.RateLimitResult), args.Error(1)
 }
 
-func (m *MockRateLimiter) IncrementRateLimit(ctx context.Context, apiKey string) error {
-	args := m.Called(ctx, apiKey)
-	return args.Error(0)
-}
-
 func (m *MockRateLimiter) GetRateLimitInfo(ctx context.Context, apiKey string) (map[string]interface{}, error) {
 	args := m.Called(ctx, apiKey)
 	if args.Get(0) == nil {
@@ -39,9 +34,22 @@ func (m *MockRateLimiter) GetRateLimitInfo(ctx context.Context, apiKey string) (
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockRateLimiter) ShouldRateLimit(ctx context.Context, domain string, descriptors [][]limiter.KV) ([]limiter.DescriptorStatus, limiter.OverallStatus, error) {
+	args := m.Called(ctx, domain, descriptors)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(limiter.OverallStatus), args.Error(2)
+	}
+	return args.Get(0).([]limiter.DescriptorStatus), args.Get(1).(limiter.OverallStatus), args.Error(2)
+}
+
+func (m *MockRateLimiter) ResolvePolicy(ctx context.Context, apiKey, path string) (limiter.ResolvedPolicy, bool) {
+	args := m.Called(ctx, apiKey, path)
+	return args.Get(0).(limiter.ResolvedPolicy), args.Bool(1)
+}
+
 func TestGatewayHandler_HealthCheck(t *testing.T) {
 	// Setup
-	logger, _ := zap.NewDevelopment()
+	logger := slog.Default()
 	cfg := &config.Config{}
 	rateLimiter := &MockRateLimiter{}
 	
@@ -74,7 +82,7 @@ func TestGatewayHandler_HealthCheck(t *testing.T) {
 
 func TestGatewayHandler_GetRateLimitInfo(t *testing.T) {
 	// Setup
-	logger, _ := zap.NewDevelopment()
+	logger := slog.Default()
 	cfg := &config.Config{}
 	rateLimiter := &MockRateLimiter{}
 	
@@ -129,7 +137,7 @@ func TestGatewayHandler_GetRateLimitInfo(t *testing.T) {
 
 func TestGatewayHandler_GetRateLimitInfo_NoAPIKey(t *testing.T) {
 	// Setup
-	logger, _ := zap.NewDevelopment()
+	logger := slog.Default()
 	cfg := &config.Config{}
 	rateLimiter := &MockRateLimiter{}
 	
@@ -160,7 +168,7 @@ func TestGatewayHandler_GetRateLimitInfo_NoAPIKey(t *testing.T) {
 
 func TestGatewayHandler_GetRateLimitInfo_Error(t *testing.T) {
 	// Setup
-	logger, _ := zap.NewDevelopment()
+	logger := slog.Default()
 	cfg := &config.Config{}
 	rateLimiter := &MockRateLimiter{}
 	
@@ -0,0 +1,190 @@
+package limiter
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"rate-limiting-gateway/internal/breaker"
+	"rate-limiting-gateway/internal/storage"
+)
+
+// BackendLimiter tracks the health and request rate of each upstream
+// backend, as opposed to RateLimiter, which tracks each client. It pairs an
+// in-memory circuit breaker per backend (see breaker.CircuitBreaker) with
+// Redis-backed counters so gateway instances fail fast against a backend
+// that's throwing errors, instead of piling retries onto something already
+// unhealthy.
+type BackendLimiter struct {
+	redis  *storage.RedisClient
+	logger *slog.Logger
+	config BackendLimiterConfig
+
+	mu       sync.Mutex
+	breakers map[string]*breaker.CircuitBreaker
+}
+
+// BackendLimiterConfig controls how a backend's circuit breaker trips and
+// recovers, and how strictly a degraded (half-open) backend's concurrency
+// is capped.
+type BackendLimiterConfig struct {
+	Breaker breaker.Config
+	// DegradedConcurrency caps in-flight requests to a half-open backend,
+	// via a Redis-backed semaphore shared across gateway instances.
+	DegradedConcurrency int
+	// CounterWindow is the TTL on the rolling backend:<name>:rps/errors
+	// counters surfaced through GetBackendState.
+	CounterWindow time.Duration
+}
+
+// DefaultBackendLimiterConfig returns reasonable defaults for a backend
+// circuit breaker.
+func DefaultBackendLimiterConfig() BackendLimiterConfig {
+	return BackendLimiterConfig{
+		Breaker:             breaker.DefaultConfig(),
+		DegradedConcurrency: 10,
+		CounterWindow:       10 * time.Second,
+	}
+}
+
+// NewBackendLimiter creates a new backend limiter.
+func NewBackendLimiter(redis *storage.RedisClient, config BackendLimiterConfig, logger *slog.Logger) *BackendLimiter {
+	return &BackendLimiter{
+		redis:    redis,
+		logger:   logger,
+		config:   config,
+		breakers: make(map[string]*breaker.CircuitBreaker),
+	}
+}
+
+func (b *BackendLimiter) breakerFor(backend string) *breaker.CircuitBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb, ok := b.breakers[backend]
+	if !ok {
+		cb = breaker.NewCircuitBreaker(b.config.Breaker)
+		b.breakers[backend] = cb
+	}
+	return cb
+}
+
+// BackendDecision is the outcome of Before, telling the caller whether to
+// forward the request and what After needs to know once it completes.
+type BackendDecision struct {
+	// Allowed is false when the backend's breaker is open; the caller
+	// should fail fast (e.g. 503) without forwarding the request.
+	Allowed bool
+	// Degraded is true when the backend is half-open. The request holds one
+	// of its limited concurrency slots only when Allowed is also true.
+	Degraded bool
+
+	slotHeld bool
+}
+
+// Before decides whether a request to backend should be forwarded. When the
+// breaker is open it fails fast. When half-open (degraded), it additionally
+// enforces DegradedConcurrency via a Redis semaphore shared across gateway
+// instances, so a recovering backend isn't immediately swamped again.
+func (b *BackendLimiter) Before(ctx context.Context, backend string) (BackendDecision, error) {
+	cb := b.breakerFor(backend)
+
+	if !cb.Allow() {
+		return BackendDecision{Allowed: false}, nil
+	}
+
+	if cb.State() != breaker.HalfOpen {
+		return BackendDecision{Allowed: true}, nil
+	}
+
+	granted, err := b.redis.AcquireBackendSlot(ctx, backend, b.config.DegradedConcurrency)
+	if err != nil {
+		return BackendDecision{}, err
+	}
+	if !granted {
+		return BackendDecision{Allowed: false, Degraded: true}, nil
+	}
+
+	return BackendDecision{Allowed: true, Degraded: true, slotHeld: true}, nil
+}
+
+// After records the outcome of a request Before allowed through: it updates
+// the breaker's rolling error rate, the Redis rps/errors/latency counters
+// surfaced via GetBackendState, and releases any concurrency slot held.
+func (b *BackendLimiter) After(ctx context.Context, backend string, decision BackendDecision, success bool, latency time.Duration) {
+	if decision.slotHeld {
+		if err := b.redis.ReleaseBackendSlot(ctx, backend); err != nil {
+			b.logger.Warn("Failed to release backend concurrency slot",
+				slog.String("backend", backend),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	b.breakerFor(backend).RecordResult(success)
+
+	if err := b.redis.RecordBackendRequest(ctx, backend, b.config.CounterWindow); err != nil {
+		b.logger.Warn("Failed to record backend request counter",
+			slog.String("backend", backend),
+			slog.Any("error", err),
+		)
+	}
+	if !success {
+		if err := b.redis.RecordBackendError(ctx, backend, b.config.CounterWindow); err != nil {
+			b.logger.Warn("Failed to record backend error counter",
+				slog.String("backend", backend),
+				slog.Any("error", err),
+			)
+		}
+	}
+	if err := b.redis.RecordBackendLatency(ctx, backend, latency.Milliseconds()); err != nil {
+		b.logger.Warn("Failed to record backend latency sample",
+			slog.String("backend", backend),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// BackendState is a point-in-time snapshot of one backend's health, for
+// /admin/backends.
+type BackendState struct {
+	Backend      string `json:"backend"`
+	Online       bool   `json:"online"`
+	State        string `json:"state"`
+	Requests     int64  `json:"requests"`
+	Errors       int64  `json:"errors"`
+	LatencyP99Ms int64  `json:"latency_p99_ms"`
+}
+
+// GetBackendState reports backend's current breaker state and Redis
+// counters, for /admin/backends.
+func (b *BackendLimiter) GetBackendState(ctx context.Context, backend string) (BackendState, error) {
+	state := b.breakerFor(backend).State()
+
+	requests, errors, latencyP99, err := b.redis.GetBackendStats(ctx, backend)
+	if err != nil {
+		return BackendState{}, err
+	}
+
+	return BackendState{
+		Backend:      backend,
+		Online:       state != breaker.Open,
+		State:        state.String(),
+		Requests:     requests,
+		Errors:       errors,
+		LatencyP99Ms: latencyP99,
+	}, nil
+}
+
+// ForceOpen trips backend's breaker regardless of its observed error rate,
+// for an operator-initiated circuit break via /admin/backends.
+func (b *BackendLimiter) ForceOpen(backend string) {
+	b.breakerFor(backend).ForceOpen()
+}
+
+// ForceClose resets backend's breaker to closed, for an operator clearing a
+// breaker once they've confirmed the backend has recovered.
+func (b *BackendLimiter) ForceClose(backend string) {
+	b.breakerFor(backend).ForceClose()
+}
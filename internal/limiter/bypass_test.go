@@ -0,0 +1,32 @@
+package limiter
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_IsBypassed_ConfigKeyTakesPrecedenceOverCIDR(t *testing.T) {
+	rl := NewRateLimiter(nil, RateLimitConfig{
+		BypassKeys:  []string{"internal-key"},
+		BypassCIDRs: []string{"10.0.0.0/8"},
+	}, slog.Default())
+
+	bypassed, reason := rl.isBypassed(context.Background(), "internal-key", "10.1.2.3")
+
+	assert.True(t, bypassed)
+	assert.Equal(t, "config_key", reason)
+}
+
+func TestRateLimiter_IsBypassed_CIDRMatch(t *testing.T) {
+	rl := NewRateLimiter(nil, RateLimitConfig{
+		BypassCIDRs: []string{"10.0.0.0/8"},
+	}, slog.Default())
+
+	bypassed, reason := rl.isBypassed(context.Background(), "some-key", "10.1.2.3")
+
+	assert.True(t, bypassed)
+	assert.Equal(t, "cidr", reason)
+}
@@ -0,0 +1,101 @@
+package limiter
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DescriptorRule declares a rate limit for one node of a descriptor tree,
+// e.g. "domain: api / key: user_id / value: <specific-user>" with its own
+// unit+limit override. Rules nest via Descriptors so operators can express
+// Envoy-style hierarchical config: a default for a key, overridden for
+// specific values.
+type DescriptorRule struct {
+	Key         string           `yaml:"key"`
+	Value       string           `yaml:"value,omitempty"`
+	RateLimit   DescriptorLimit  `yaml:"rate_limit"`
+	Descriptors []DescriptorRule `yaml:"descriptors,omitempty"`
+}
+
+// DescriptorLimit is the unit+requests pair attached to a DescriptorRule.
+type DescriptorLimit struct {
+	Unit            string `yaml:"unit"` // "second", "minute", "hour", "day"
+	RequestsPerUnit int    `yaml:"requests_per_unit"`
+}
+
+// Duration returns the time.Duration for the rule's unit, defaulting to a
+// minute for an unrecognized or empty unit.
+func (l DescriptorLimit) Duration() time.Duration {
+	switch l.Unit {
+	case "second":
+		return time.Second
+	case "hour":
+		return time.Hour
+	case "day":
+		return 24 * time.Hour
+	default:
+		return time.Minute
+	}
+}
+
+// DomainConfig is the top-level YAML document for a single domain, e.g.
+// "domain: api" with its descriptor tree.
+type DomainConfig struct {
+	Domain      string           `yaml:"domain"`
+	Descriptors []DescriptorRule `yaml:"descriptors"`
+}
+
+// LoadDescriptorConfig reads a domain's descriptor rules from a YAML file on
+// disk, in the same hierarchical shape Envoy's ratelimit sidecar uses.
+func LoadDescriptorConfig(path string) (*DomainConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor config %s: %w", path, err)
+	}
+
+	var cfg DomainConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor config %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// match walks the descriptor tree looking for the most specific rule that
+// matches the given key/value entries, falling back to a key-only match,
+// then to no match (nil).
+func (c *DomainConfig) match(entries []KV) *DescriptorRule {
+	rules := c.Descriptors
+	var best *DescriptorRule
+
+	for _, kv := range entries {
+		found := findRule(rules, kv)
+		if found == nil {
+			break
+		}
+		best = found
+		rules = found.Descriptors
+	}
+
+	return best
+}
+
+func findRule(rules []DescriptorRule, kv KV) *DescriptorRule {
+	var keyOnly *DescriptorRule
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Key != kv.Key {
+			continue
+		}
+		if rule.Value == kv.Value && kv.Value != "" {
+			return rule
+		}
+		if rule.Value == "" {
+			keyOnly = rule
+		}
+	}
+	return keyOnly
+}
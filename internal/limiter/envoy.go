@@ -0,0 +1,103 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// KV is a single descriptor entry, mirroring Envoy's RateLimitDescriptor.Entry
+// (key/value pair such as {"key": "user_id", "value": "42"}).
+type KV struct {
+	Key   string
+	Value string
+}
+
+// OverallStatus mirrors Envoy's RateLimitResponse.Code.
+type OverallStatus int
+
+const (
+	StatusUnknown OverallStatus = iota
+	StatusOK
+	StatusOverLimit
+)
+
+// DescriptorStatus mirrors Envoy's RateLimitResponse.DescriptorStatus: the
+// per-descriptor verdict plus the limit that produced it.
+type DescriptorStatus struct {
+	Code               OverallStatus
+	CurrentLimit       int
+	LimitRemaining     int
+	DurationUntilReset int64 // seconds
+}
+
+// RateLimitServiceServer implements the Envoy RateLimitService gRPC API
+// (envoy.service.ratelimit.v3.RateLimitService) on top of the same Redis
+// counters used by the HTTP gateway, so Envoy sidecars can call flowguard
+// directly instead of going through the proxied path.
+type RateLimitServiceServer struct {
+	limiter RateLimiterInterface
+	logger  *slog.Logger
+}
+
+// NewRateLimitServiceServer creates a new Envoy-compatible rate limit service.
+func NewRateLimitServiceServer(limiter RateLimiterInterface, logger *slog.Logger) *RateLimitServiceServer {
+	return &RateLimitServiceServer{
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// Register registers the service on the given gRPC server.
+func (s *RateLimitServiceServer) Register(server *grpc.Server) {
+	RegisterRateLimitServiceServer(server, s)
+}
+
+// ShouldRateLimit handles the Envoy ShouldRateLimit RPC: a domain plus a list
+// of ordered descriptors, returning an overall code and a per-descriptor
+// status so Envoy can decide whether to reject the request.
+func (s *RateLimitServiceServer) ShouldRateLimit(ctx context.Context, req *RateLimitRequest) (*RateLimitResponse, error) {
+	statuses, overall, err := s.limiter.ShouldRateLimit(ctx, req.Domain, req.Descriptors)
+	if err != nil {
+		s.logger.Error("ShouldRateLimit failed",
+			slog.String("domain", req.Domain),
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("shouldratelimit: %w", err)
+	}
+
+	return &RateLimitResponse{
+		OverallCode: overall,
+		Statuses:    statuses,
+	}, nil
+}
+
+// RateLimitRequest mirrors envoy.service.ratelimit.v3.RateLimitRequest.
+type RateLimitRequest struct {
+	Domain      string
+	Descriptors [][]KV
+}
+
+// RateLimitResponse mirrors envoy.service.ratelimit.v3.RateLimitResponse.
+type RateLimitResponse struct {
+	OverallCode OverallStatus
+	Statuses    []DescriptorStatus
+}
+
+// descriptorKey builds the Redis key for a single descriptor by joining its
+// ordered key/value entries, e.g. "ratelimit:api:user_id:42".
+func descriptorKey(domain string, descriptor []KV) string {
+	parts := make([]string, 0, len(descriptor)+1)
+	parts = append(parts, domain)
+	for _, kv := range descriptor {
+		if kv.Value != "" {
+			parts = append(parts, kv.Key, kv.Value)
+		} else {
+			parts = append(parts, kv.Key)
+		}
+	}
+	return "ratelimit:" + strings.Join(parts, ":")
+}
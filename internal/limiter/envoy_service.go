@@ -0,0 +1,66 @@
+package limiter
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// envoyRateLimitService is the narrow interface the generated Envoy stubs
+// would normally require. RateLimitRequest/RateLimitResponse are hand-rolled
+// stand-ins for the generated message types (see envoy_wire.go) rather than
+// real protoc-gen-go output, since this tree has no protobuf toolchain to
+// generate from envoy's ratelimit.proto.
+type envoyRateLimitService interface {
+	ShouldRateLimit(ctx context.Context, req *RateLimitRequest) (*RateLimitResponse, error)
+}
+
+// GRPCServerOptions returns the grpc.ServerOption(s) the caller must pass to
+// grpc.NewServer before calling Register. Without ForceServerCodec, grpc-go's
+// default "proto" codec type-asserts every message to proto.Message and
+// rejects RateLimitRequest/RateLimitResponse outright; envoyProtoCodec
+// encodes and decodes them to the same wire bytes a real
+// envoy.service.ratelimit.v3 stub would, so an Envoy sidecar's ShouldRateLimit
+// calls still work.
+func GRPCServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.ForceServerCodec(envoyProtoCodec{})}
+}
+
+// rateLimitServiceDesc describes the RateLimitService gRPC service, matching
+// envoy.service.ratelimit.v3.RateLimitService from the Envoy data-plane-api.
+var rateLimitServiceDesc = grpc.ServiceDesc{
+	ServiceName: "envoy.service.ratelimit.v3.RateLimitService",
+	HandlerType: (*envoyRateLimitService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ShouldRateLimit",
+			Handler:    shouldRateLimitHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "flowguard/limiter/envoy.proto",
+}
+
+func shouldRateLimitHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(envoyRateLimitService).ShouldRateLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/envoy.service.ratelimit.v3.RateLimitService/ShouldRateLimit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(envoyRateLimitService).ShouldRateLimit(ctx, req.(*RateLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RegisterRateLimitServiceServer registers srv as the implementation of the
+// RateLimitService on the given gRPC server.
+func RegisterRateLimitServiceServer(s *grpc.Server, srv envoyRateLimitService) {
+	s.RegisterService(&rateLimitServiceDesc, srv)
+}
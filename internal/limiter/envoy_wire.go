@@ -0,0 +1,342 @@
+package limiter
+
+import (
+	"fmt"
+)
+
+// This file hand-implements just enough of the protobuf wire format to
+// marshal/unmarshal RateLimitRequest and RateLimitResponse the same way
+// protoc-gen-go would for envoy.service.ratelimit.v3, using the field
+// numbers from that schema. It exists because this tree has no protobuf
+// toolchain available to generate real stubs from ratelimit.proto; encoding
+// to the same wire layout by hand is what lets a real Envoy sidecar (whose
+// encoder *is* generated from that schema) talk to envoyProtoCodec below.
+// Fields we don't populate (hits_addend, RateLimitDescriptor.limit,
+// response/request headers, raw_body, RateLimit.unit/name) are simply
+// omitted on encode and skipped on decode, which proto3's wire format
+// treats as unset — forward-compatible in both directions.
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendVarint(dst []byte, v uint64) []byte {
+	for v >= 0x80 {
+		dst = append(dst, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
+
+func appendTag(dst []byte, field int, wireType int) []byte {
+	return appendVarint(dst, uint64(field)<<3|uint64(wireType))
+}
+
+func appendLengthDelimited(dst []byte, field int, payload []byte) []byte {
+	dst = appendTag(dst, field, wireBytes)
+	dst = appendVarint(dst, uint64(len(payload)))
+	return append(dst, payload...)
+}
+
+func consumeVarint(data []byte) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	for i, b := range data {
+		if shift >= 64 {
+			return 0, 0, fmt.Errorf("envoy: varint too long")
+		}
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, i + 1, nil
+		}
+		shift += 7
+	}
+	return 0, 0, fmt.Errorf("envoy: truncated varint")
+}
+
+// consumeField reads the next tag plus its payload and reports how many
+// bytes of data it consumed. For wireBytes fields, payload is the inner
+// bytes (length prefix stripped); for wireVarint fields the decoded varint
+// value is returned in varintValue.
+func consumeField(data []byte) (field int, wireType int, varintValue uint64, payload []byte, n int, err error) {
+	tag, tn, err := consumeVarint(data)
+	if err != nil {
+		return 0, 0, 0, nil, 0, err
+	}
+	field = int(tag >> 3)
+	wireType = int(tag & 0x7)
+	n = tn
+
+	switch wireType {
+	case wireVarint:
+		val, vn, err := consumeVarint(data[n:])
+		if err != nil {
+			return 0, 0, 0, nil, 0, err
+		}
+		varintValue = val
+		n += vn
+	case wireBytes:
+		length, ln, err := consumeVarint(data[n:])
+		if err != nil {
+			return 0, 0, 0, nil, 0, err
+		}
+		n += ln
+		if uint64(len(data[n:])) < length {
+			return 0, 0, 0, nil, 0, fmt.Errorf("envoy: truncated length-delimited field %d", field)
+		}
+		payload = data[n : n+int(length)]
+		n += int(length)
+	default:
+		return 0, 0, 0, nil, 0, fmt.Errorf("envoy: unsupported wire type %d on field %d", wireType, field)
+	}
+	return field, wireType, varintValue, payload, n, nil
+}
+
+func marshalRateLimitRequest(req *RateLimitRequest) []byte {
+	var buf []byte
+	if req.Domain != "" {
+		buf = appendLengthDelimited(buf, 1, []byte(req.Domain))
+	}
+	for _, descriptor := range req.Descriptors {
+		buf = appendLengthDelimited(buf, 2, marshalDescriptor(descriptor))
+	}
+	return buf
+}
+
+func marshalDescriptor(entries []KV) []byte {
+	var buf []byte
+	for _, kv := range entries {
+		buf = appendLengthDelimited(buf, 1, marshalEntry(kv))
+	}
+	return buf
+}
+
+func marshalEntry(kv KV) []byte {
+	var buf []byte
+	if kv.Key != "" {
+		buf = appendLengthDelimited(buf, 1, []byte(kv.Key))
+	}
+	if kv.Value != "" {
+		buf = appendLengthDelimited(buf, 2, []byte(kv.Value))
+	}
+	return buf
+}
+
+func unmarshalRateLimitRequest(data []byte, req *RateLimitRequest) error {
+	for len(data) > 0 {
+		field, wireType, _, payload, n, err := consumeField(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+
+		if wireType != wireBytes {
+			continue
+		}
+		switch field {
+		case 1:
+			req.Domain = string(payload)
+		case 2:
+			descriptor, err := unmarshalDescriptor(payload)
+			if err != nil {
+				return err
+			}
+			req.Descriptors = append(req.Descriptors, descriptor)
+		}
+	}
+	return nil
+}
+
+func unmarshalDescriptor(data []byte) ([]KV, error) {
+	var entries []KV
+	for len(data) > 0 {
+		field, wireType, _, payload, n, err := consumeField(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+
+		if wireType != wireBytes || field != 1 {
+			continue
+		}
+		kv, err := unmarshalEntry(payload)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, kv)
+	}
+	return entries, nil
+}
+
+func unmarshalEntry(data []byte) (KV, error) {
+	var kv KV
+	for len(data) > 0 {
+		field, wireType, _, payload, n, err := consumeField(data)
+		if err != nil {
+			return KV{}, err
+		}
+		data = data[n:]
+
+		if wireType != wireBytes {
+			continue
+		}
+		switch field {
+		case 1:
+			kv.Key = string(payload)
+		case 2:
+			kv.Value = string(payload)
+		}
+	}
+	return kv, nil
+}
+
+func marshalRateLimitResponse(resp *RateLimitResponse) []byte {
+	var buf []byte
+	buf = appendTag(buf, 1, wireVarint)
+	buf = appendVarint(buf, uint64(resp.OverallCode))
+	for _, status := range resp.Statuses {
+		buf = appendLengthDelimited(buf, 2, marshalDescriptorStatus(status))
+	}
+	return buf
+}
+
+func marshalDescriptorStatus(status DescriptorStatus) []byte {
+	var buf []byte
+	buf = appendTag(buf, 1, wireVarint)
+	buf = appendVarint(buf, uint64(status.Code))
+
+	if status.CurrentLimit > 0 {
+		var rateLimit []byte
+		rateLimit = appendTag(rateLimit, 1, wireVarint)
+		rateLimit = appendVarint(rateLimit, uint64(status.CurrentLimit))
+		buf = appendLengthDelimited(buf, 2, rateLimit)
+	}
+
+	buf = appendTag(buf, 3, wireVarint)
+	buf = appendVarint(buf, uint64(status.LimitRemaining))
+
+	if status.DurationUntilReset > 0 {
+		var duration []byte
+		duration = appendTag(duration, 1, wireVarint)
+		duration = appendVarint(duration, uint64(status.DurationUntilReset))
+		buf = appendLengthDelimited(buf, 4, duration)
+	}
+
+	return buf
+}
+
+func unmarshalRateLimitResponse(data []byte, resp *RateLimitResponse) error {
+	for len(data) > 0 {
+		field, wireType, value, payload, n, err := consumeField(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+
+		switch {
+		case field == 1 && wireType == wireVarint:
+			resp.OverallCode = OverallStatus(value)
+		case field == 2 && wireType == wireBytes:
+			status, err := unmarshalDescriptorStatus(payload)
+			if err != nil {
+				return err
+			}
+			resp.Statuses = append(resp.Statuses, status)
+		}
+	}
+	return nil
+}
+
+func unmarshalDescriptorStatus(data []byte) (DescriptorStatus, error) {
+	var status DescriptorStatus
+	for len(data) > 0 {
+		field, wireType, value, payload, n, err := consumeField(data)
+		if err != nil {
+			return DescriptorStatus{}, err
+		}
+		data = data[n:]
+
+		switch {
+		case field == 1 && wireType == wireVarint:
+			status.Code = OverallStatus(value)
+		case field == 2 && wireType == wireBytes:
+			limit, err := unmarshalRateLimit(payload)
+			if err != nil {
+				return DescriptorStatus{}, err
+			}
+			status.CurrentLimit = limit
+		case field == 3 && wireType == wireVarint:
+			status.LimitRemaining = int(value)
+		case field == 4 && wireType == wireBytes:
+			seconds, err := unmarshalDuration(payload)
+			if err != nil {
+				return DescriptorStatus{}, err
+			}
+			status.DurationUntilReset = seconds
+		}
+	}
+	return status, nil
+}
+
+func unmarshalRateLimit(data []byte) (int, error) {
+	for len(data) > 0 {
+		field, wireType, value, _, n, err := consumeField(data)
+		if err != nil {
+			return 0, err
+		}
+		data = data[n:]
+		if field == 1 && wireType == wireVarint {
+			return int(value), nil
+		}
+	}
+	return 0, nil
+}
+
+func unmarshalDuration(data []byte) (int64, error) {
+	for len(data) > 0 {
+		field, wireType, value, _, n, err := consumeField(data)
+		if err != nil {
+			return 0, err
+		}
+		data = data[n:]
+		if field == 1 && wireType == wireVarint {
+			return int64(value), nil
+		}
+	}
+	return 0, nil
+}
+
+// envoyProtoCodec marshals/unmarshals RateLimitRequest and RateLimitResponse
+// to the same wire bytes protoc-gen-go would produce for their
+// ratelimit.proto counterparts, without depending on google.golang.org/protobuf.
+// It's installed via GRPCServerOptions so grpc-go never hands these types to
+// its default "proto" codec, which would reject them for not implementing
+// proto.Message.
+type envoyProtoCodec struct{}
+
+func (envoyProtoCodec) Name() string { return "proto" }
+
+func (envoyProtoCodec) Marshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case *RateLimitRequest:
+		return marshalRateLimitRequest(m), nil
+	case *RateLimitResponse:
+		return marshalRateLimitResponse(m), nil
+	default:
+		return nil, fmt.Errorf("envoy: codec cannot marshal %T", v)
+	}
+}
+
+func (envoyProtoCodec) Unmarshal(data []byte, v interface{}) error {
+	switch m := v.(type) {
+	case *RateLimitRequest:
+		*m = RateLimitRequest{}
+		return unmarshalRateLimitRequest(data, m)
+	case *RateLimitResponse:
+		*m = RateLimitResponse{}
+		return unmarshalRateLimitResponse(data, m)
+	default:
+		return fmt.Errorf("envoy: codec cannot unmarshal into %T", v)
+	}
+}
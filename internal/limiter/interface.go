@@ -4,7 +4,23 @@ import "context"
 
 // RateLimiterInterface defines the interface for rate limiting operations
 type RateLimiterInterface interface {
-	CheckRateLimit(ctx context.Context, apiKey string) (*RateLimitResult, error)
-	IncrementRateLimit(ctx context.Context, apiKey string) error
+	// CheckRateLimit takes the request path so a policy assigned to apiKey
+	// (see PolicyStore) can enforce a per-route/per-API partition instead of
+	// the gateway's global limits. It checks and consumes all of apiKey's
+	// counters atomically in one call; on rejection nothing is consumed.
+	// clientIP is used only to match a configured bypass CIDR range; pass ""
+	// if it is unavailable.
+	CheckRateLimit(ctx context.Context, apiKey, path, clientIP string) (*RateLimitResult, error)
 	GetRateLimitInfo(ctx context.Context, apiKey string) (map[string]interface{}, error)
+
+	// ResolvePolicy returns the merged policy for apiKey/path, if any
+	// policies are assigned to the key, for callers that want to inspect it
+	// without invoking CheckRateLimit (e.g. attaching it to the request
+	// context for observability headers).
+	ResolvePolicy(ctx context.Context, apiKey, path string) (ResolvedPolicy, bool)
+
+	// ShouldRateLimit implements the Envoy RateLimitService contract: given a
+	// domain and a list of ordered descriptors, it returns a status per
+	// descriptor plus an overall verdict, backed by the same Redis counters.
+	ShouldRateLimit(ctx context.Context, domain string, descriptors [][]KV) ([]DescriptorStatus, OverallStatus, error)
 } 
\ No newline at end of file
@@ -2,18 +2,201 @@ package limiter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"strings"
 	"time"
 
+	"rate-limiting-gateway/internal/cluster"
+	"rate-limiting-gateway/internal/metrics"
 	"rate-limiting-gateway/internal/storage"
-	"go.uber.org/zap"
 )
 
 // RateLimiter handles rate limiting logic
 type RateLimiter struct {
-	redis  *storage.RedisClient
-	logger *zap.Logger
-	config RateLimitConfig
+	redis            *storage.RedisClient
+	logger           *slog.Logger
+	config           RateLimitConfig
+	descriptorConfig *DomainConfig
+
+	cluster   *cluster.Cluster
+	batchers  map[cluster.Behavior]*cluster.Batcher
+	behaviors map[string]cluster.Behavior
+
+	modes        map[string]LimitMode
+	tokenBuckets map[string]TokenBucketConfig
+
+	policies *PolicyStore
+	keys     storage.KeyStore
+	metrics  *metrics.Metrics
+
+	bypassCIDRs []*net.IPNet
+}
+
+// SetPolicyStore installs the per-route/per-API policy subsystem. Once set,
+// CheckRateLimit consults it first and enforces the resolved partition for
+// keys with assigned policies, falling back to the gateway's global
+// RateLimitConfig for everyone else.
+func (r *RateLimiter) SetPolicyStore(store *PolicyStore) {
+	r.policies = store
+}
+
+// SetKeyStore installs the pluggable per-client tier backend. Once set,
+// effectiveLimits prefers a client's own RequestsPer*/MonthlyQuota overrides
+// (and honors Unlimited) ahead of the gateway's global RateLimitConfig,
+// before policy partitions are applied on top.
+func (r *RateLimiter) SetKeyStore(keys storage.KeyStore) {
+	r.keys = keys
+}
+
+// SetMetrics installs the Prometheus metrics instance so CheckRateLimit can
+// record gateway_requests_bypassed_total when a request skips enforcement.
+// Without it, bypassed requests are still allowed through, just not counted.
+func (r *RateLimiter) SetMetrics(m *metrics.Metrics) {
+	r.metrics = m
+}
+
+// LimitMode selects the algorithm RateLimiter uses to enforce an API key's
+// per-minute/hour/day limits.
+type LimitMode int
+
+const (
+	// SlidingWindow enforces each tier as a true sliding window backed by a
+	// Redis ZSET, avoiding the burst-at-the-boundary problem of fixed
+	// windows. This is the default for keys with no explicit mode set.
+	SlidingWindow LimitMode = iota
+	// TokenBucket enforces a single steady-state rate with a configurable
+	// burst allowance, backed by a Redis hash.
+	TokenBucket
+)
+
+// TokenBucketConfig holds the steady-state rate and burst capacity for an
+// API key running in TokenBucket mode.
+type TokenBucketConfig struct {
+	Rate  float64 // tokens refilled per second
+	Burst float64 // maximum tokens the bucket can hold
+}
+
+// SetKeyMode overrides the limiting algorithm for a specific API key; keys
+// without an override default to SlidingWindow.
+func (r *RateLimiter) SetKeyMode(apiKey string, mode LimitMode) {
+	if r.modes == nil {
+		r.modes = make(map[string]LimitMode)
+	}
+	r.modes[apiKey] = mode
+}
+
+func (r *RateLimiter) modeFor(apiKey string) LimitMode {
+	if r.modes == nil {
+		return SlidingWindow
+	}
+	return r.modes[apiKey]
+}
+
+// SetTokenBucketConfig configures the rate/burst for an API key running in
+// TokenBucket mode. Keys without an explicit config fall back to a bucket
+// derived from RequestsPerMinute (burst = limit, rate = limit/60).
+func (r *RateLimiter) SetTokenBucketConfig(apiKey string, cfg TokenBucketConfig) {
+	if r.tokenBuckets == nil {
+		r.tokenBuckets = make(map[string]TokenBucketConfig)
+	}
+	r.tokenBuckets[apiKey] = cfg
+}
+
+func (r *RateLimiter) tokenBucketConfigFor(apiKey string) TokenBucketConfig {
+	if cfg, ok := r.tokenBuckets[apiKey]; ok {
+		return cfg
+	}
+	return TokenBucketConfig{
+		Rate:  float64(r.config.RequestsPerMinute) / 60,
+		Burst: float64(r.config.RequestsPerMinute),
+	}
+}
+
+// SetCluster installs the peer-based distributed rate limiting subsystem.
+// Once set, CheckRateLimit consults the hash ring and short-circuits
+// locally when this node owns the key, forwarding to the owner peer
+// otherwise.
+func (r *RateLimiter) SetCluster(c *cluster.Cluster) {
+	r.cluster = c
+}
+
+// SetKeyBehavior overrides the batching behavior for a specific API key; keys
+// without an override default to cluster.NoBatching.
+func (r *RateLimiter) SetKeyBehavior(apiKey string, behavior cluster.Behavior) {
+	if r.behaviors == nil {
+		r.behaviors = make(map[string]cluster.Behavior)
+	}
+	r.behaviors[apiKey] = behavior
+}
+
+func (r *RateLimiter) behaviorFor(apiKey string) cluster.Behavior {
+	if r.behaviors == nil {
+		return cluster.NoBatching
+	}
+	return r.behaviors[apiKey]
+}
+
+// getCount returns the current counter for apiKey/windowKey, routed through
+// the cluster when one is configured so the request hits the owning peer.
+func (r *RateLimiter) getCount(ctx context.Context, apiKey, windowKey string) (int, error) {
+	if r.cluster != nil {
+		return r.cluster.CheckRateLimit(ctx, apiKey, windowKey)
+	}
+	return r.redis.GetRateLimit(ctx, apiKey, windowKey)
+}
+
+// incrementCount increments the counter for apiKey/windowKey. For keys with a
+// Batching/Global behavior it aggregates locally and flushes to the owner on
+// a short window instead of forwarding every single increment.
+func (r *RateLimiter) incrementCount(ctx context.Context, apiKey, windowKey string, limit int) (int, error) {
+	if r.cluster == nil {
+		return r.redis.IncrementRateLimit(ctx, apiKey, windowKey, limit)
+	}
+
+	behavior := r.behaviorFor(apiKey)
+	if behavior == cluster.NoBatching {
+		return r.cluster.IncrementRateLimit(ctx, apiKey, windowKey, limit)
+	}
+
+	r.batcherFor(behavior, limit).Add(apiKey+"|"+windowKey, 1)
+	// Batched increments are fire-and-forget; return the last known count.
+	return r.cluster.CheckRateLimit(ctx, apiKey, windowKey)
+}
+
+func (r *RateLimiter) batcherFor(behavior cluster.Behavior, limit int) *cluster.Batcher {
+	if r.batchers == nil {
+		r.batchers = make(map[cluster.Behavior]*cluster.Batcher)
+	}
+	if b, ok := r.batchers[behavior]; ok {
+		return b
+	}
+
+	b := cluster.NewBatcher(100*time.Millisecond, func(compositeKey string, delta int) {
+		apiKey, windowKey := splitCompositeKey(compositeKey)
+		for i := 0; i < delta; i++ {
+			if _, err := r.cluster.IncrementRateLimit(context.Background(), apiKey, windowKey, limit); err != nil {
+				r.logger.Warn("Failed to flush batched rate limit increment",
+					slog.String("api_key", apiKey),
+					slog.Any("error", err),
+				)
+				return
+			}
+		}
+	})
+	r.batchers[behavior] = b
+	return b
+}
+
+func splitCompositeKey(k string) (apiKey, windowKey string) {
+	for i := len(k) - 1; i >= 0; i-- {
+		if k[i] == '|' {
+			return k[:i], k[i+1:]
+		}
+	}
+	return k, ""
 }
 
 // RateLimitConfig holds rate limiting configuration
@@ -23,6 +206,15 @@ type RateLimitConfig struct {
 	RequestsPerDay    int
 	MonthlyQuota      int
 	WindowSize        time.Duration
+
+	// BypassKeys are API keys that skip rate limiting entirely, still
+	// authenticated and logged as usual. Combined with the dynamic Redis
+	// bypass set (see storage.RedisClient.IsBypassKey) so operators can add
+	// keys at runtime without a redeploy.
+	BypassKeys []string
+	// BypassCIDRs are client IP ranges (e.g. "10.0.0.0/8") that skip rate
+	// limiting entirely, for internal callers that must never be throttled.
+	BypassCIDRs []string
 }
 
 // RateLimitResult represents the result of a rate limit check
@@ -34,32 +226,111 @@ type RateLimitResult struct {
 	Window     string
 	QuotaUsed  int
 	QuotaLimit int
+
+	// BypassReason is set when Window is "bypass", identifying which
+	// mechanism matched ("config_key", "cidr", "dynamic_key", or "policy").
+	BypassReason string
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(redis *storage.RedisClient, config RateLimitConfig, logger *zap.Logger) *RateLimiter {
+// NewRateLimiter creates a new rate limiter. Entries in config.BypassCIDRs
+// that fail to parse are logged and skipped rather than failing startup,
+// consistent with the gateway's general preference for degrading gracefully
+// on optional configuration.
+func NewRateLimiter(redis *storage.RedisClient, config RateLimitConfig, logger *slog.Logger) *RateLimiter {
+	var bypassCIDRs []*net.IPNet
+	for _, raw := range config.BypassCIDRs {
+		_, cidr, err := net.ParseCIDR(raw)
+		if err != nil {
+			logger.Warn("Failed to parse bypass CIDR, skipping",
+				slog.String("cidr", raw),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		bypassCIDRs = append(bypassCIDRs, cidr)
+	}
+
 	return &RateLimiter{
-		redis:  redis,
-		logger: logger,
-		config: config,
+		redis:       redis,
+		logger:      logger,
+		config:      config,
+		bypassCIDRs: bypassCIDRs,
 	}
 }
 
-// CheckRateLimit checks if a request is allowed based on rate limits
-func (r *RateLimiter) CheckRateLimit(ctx context.Context, apiKey string) (*RateLimitResult, error) {
+// CheckRateLimit checks if a request is allowed based on rate limits. path
+// is the incoming request path (not the Gin route pattern, which for the
+// gateway's catch-all proxy route is the same for every request); it is
+// used to resolve a per-route/per-API partition from any policy assigned to
+// apiKey, falling back to the gateway's global RateLimitConfig otherwise.
+// clientIP is used only to match BypassCIDRs; pass an empty string if it is
+// unavailable or bypass-by-IP is not in use.
+func (r *RateLimiter) CheckRateLimit(ctx context.Context, apiKey, path, clientIP string) (*RateLimitResult, error) {
 	now := time.Now()
-	
-	// Check monthly quota first
+
+	if bypassed, reason := r.isBypassed(ctx, apiKey, clientIP); bypassed {
+		if r.metrics != nil {
+			r.metrics.RecordBypass(reason)
+		}
+		return &RateLimitResult{
+			Allowed:      true,
+			ResetTime:    now.Add(time.Minute),
+			Window:       "bypass",
+			BypassReason: reason,
+		}, nil
+	}
+
+	limits, quotaLimit, unlimited, denied := r.effectiveLimits(ctx, apiKey, path)
+	if denied {
+		r.logger.Warn("Request denied by policy ACL",
+			slog.String("api_key", apiKey),
+			slog.String("path", path),
+		)
+		return &RateLimitResult{
+			Allowed:    false,
+			Remaining:  0,
+			ResetTime:  now.Add(24 * time.Hour),
+			Limit:      0,
+			Window:     "denied",
+			QuotaLimit: quotaLimit,
+		}, nil
+	}
+
+	// Unlimited clients skip quota enforcement entirely, but their usage is
+	// still tracked (via a plain increment, never denied) so it shows up in
+	// reporting.
+	if unlimited {
+		quotaUsed, err := r.redis.IncrementMonthlyQuota(ctx, apiKey, quotaLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record monthly quota usage: %w", err)
+		}
+		return &RateLimitResult{
+			Allowed:    true,
+			Remaining:  limits.RequestsPerMinute,
+			ResetTime:  now.Add(time.Minute),
+			Limit:      limits.RequestsPerMinute,
+			Window:     "unlimited",
+			QuotaUsed:  quotaUsed,
+			QuotaLimit: quotaLimit,
+		}, nil
+	}
+
+	// Peek (not consume) the monthly quota first. Committing it before the
+	// per-tier checks below used to mean a request that was ultimately
+	// rejected by a later tier still left the quota incremented with no
+	// rollback anywhere — see checkSlidingWindows/checkTokenBucket, which now
+	// only commit the quota increment once every other tier has confirmed
+	// there's room.
 	quotaUsed, err := r.redis.GetMonthlyQuota(ctx, apiKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get monthly quota: %w", err)
 	}
 
-	if quotaUsed >= r.config.MonthlyQuota {
+	if quotaLimit > 0 && quotaUsed >= quotaLimit {
 		r.logger.Warn("Monthly quota exceeded",
-			zap.String("api_key", apiKey),
-			zap.Int("quota_used", quotaUsed),
-			zap.Int("quota_limit", r.config.MonthlyQuota),
+			slog.String("api_key", apiKey),
+			slog.Int("quota_used", quotaUsed),
+			slog.Int("quota_limit", quotaLimit),
 		)
 		return &RateLimitResult{
 			Allowed:    false,
@@ -68,36 +339,252 @@ func (r *RateLimiter) CheckRateLimit(ctx context.Context, apiKey string) (*RateL
 			Limit:      0,
 			Window:     "monthly",
 			QuotaUsed:  quotaUsed,
-			QuotaLimit: r.config.MonthlyQuota,
+			QuotaLimit: quotaLimit,
 		}, nil
 	}
 
-	// Check different time windows
+	if r.modeFor(apiKey) == TokenBucket {
+		return r.checkTokenBucket(ctx, apiKey, quotaLimit, now)
+	}
+	return r.checkSlidingWindows(ctx, apiKey, quotaLimit, limits, now)
+}
+
+// commitMonthlyQuota records one request against apiKey's monthly quota,
+// called only once every other tier (sliding windows or token bucket) has
+// already confirmed it has room, so a request that's ultimately allowed
+// never leaves a tier uncounted and a request that's ultimately rejected
+// never leaves the quota incremented. It still uses the atomic
+// check-and-increment rather than a plain increment, since the peek earlier
+// in CheckRateLimit only makes overshoot far less likely, not impossible: a
+// concurrent request can still land between the peek and this commit.
+func (r *RateLimiter) commitMonthlyQuota(ctx context.Context, apiKey string, quotaLimit int) (allowed bool, quotaUsed int, err error) {
+	return r.redis.CheckAndIncrementMonthlyQuota(ctx, apiKey, quotaLimit)
+}
+
+// isBypassed reports whether apiKey or clientIP match a configured bypass
+// mechanism, in which case CheckRateLimit skips enforcement entirely while
+// still letting the request through authenticated and logged. reason
+// identifies which mechanism matched, for the gateway_requests_bypassed_total
+// metric and the X-RateLimit-Bypass-Reason header.
+//
+// Signed internal tokens, also mentioned as a bypass mechanism in the
+// original ask, aren't handled here: the gateway has no token-signing or
+// verification infrastructure to validate one against, so that case is left
+// for a future change rather than bolted on ad hoc.
+func (r *RateLimiter) isBypassed(ctx context.Context, apiKey, clientIP string) (bypassed bool, reason string) {
+	for _, k := range r.config.BypassKeys {
+		if k == apiKey {
+			return true, "config_key"
+		}
+	}
+
+	if clientIP != "" {
+		if ip := net.ParseIP(clientIP); ip != nil {
+			for _, cidr := range r.bypassCIDRs {
+				if cidr.Contains(ip) {
+					return true, "cidr"
+				}
+			}
+		}
+	}
+
+	ok, err := r.redis.IsBypassKey(ctx, apiKey)
+	if err != nil {
+		r.logger.Warn("Failed to check dynamic bypass key set",
+			slog.String("api_key", apiKey),
+			slog.Any("error", err),
+		)
+	} else if ok {
+		return true, "dynamic_key"
+	}
+
+	if r.policies != nil && r.policies.BypassRateLimit(apiKey) {
+		return true, "policy"
+	}
+
+	return false, ""
+}
+
+// allowsAPI reports whether path is permitted under allowedAPIs, matched by
+// prefix like ResolvedPolicy.AllowsPath. A nil/empty allowedAPIs means every
+// path is allowed.
+func allowsAPI(allowedAPIs []string, path string) bool {
+	if len(allowedAPIs) == 0 {
+		return true
+	}
+	for _, api := range allowedAPIs {
+		if strings.HasPrefix(path, api) {
+			return true
+		}
+	}
+	return false
+}
+
+// effectiveLimits resolves apiKey's effective per-tier limits and monthly
+// quota for path. The client's own KeyStore overrides (if a KeyStore is
+// configured) replace the gateway's global RateLimitConfig first; the
+// policy store (if installed) is then applied on top, since a policy's
+// per-route partition is more specific than a client's blanket tier.
+// unlimited is true when the client's record opts out of enforcement
+// entirely; denied is true when the client's AllowedAPIs or a policy
+// explicitly denies the matched API, which fails the request closed
+// regardless of rate or unlimited status.
+func (r *RateLimiter) effectiveLimits(ctx context.Context, apiKey, path string) (limits PartitionLimit, quota int, unlimited, denied bool) {
+	limits = PartitionLimit{
+		RequestsPerMinute: r.config.RequestsPerMinute,
+		RequestsPerHour:   r.config.RequestsPerHour,
+		RequestsPerDay:    r.config.RequestsPerDay,
+	}
+	quota = r.config.MonthlyQuota
+
+	if r.keys != nil {
+		info, err := r.keys.Lookup(ctx, apiKey)
+		if err != nil && !errors.Is(err, storage.ErrClientNotFound) {
+			r.logger.Warn("Failed to look up client for rate limiting",
+				slog.String("api_key", apiKey),
+				slog.Any("error", err),
+			)
+		} else if err == nil {
+			if !allowsAPI(info.AllowedAPIs, path) {
+				return PartitionLimit{}, quota, false, true
+			}
+
+			unlimited = info.Unlimited
+			if info.RequestsPerMinute > 0 {
+				limits.RequestsPerMinute = info.RequestsPerMinute
+			}
+			if info.RequestsPerHour > 0 {
+				limits.RequestsPerHour = info.RequestsPerHour
+			}
+			if info.RequestsPerDay > 0 {
+				limits.RequestsPerDay = info.RequestsPerDay
+			}
+			if info.MonthlyQuota > 0 {
+				quota = info.MonthlyQuota
+			}
+		}
+	}
+
+	if r.policies == nil {
+		return limits, quota, unlimited, false
+	}
+
+	resolved, found := r.policies.Resolve(apiKey, path)
+	if !found {
+		return limits, quota, unlimited, false
+	}
+	if resolved.Denied {
+		return PartitionLimit{}, quota, false, true
+	}
+	if !resolved.AllowsPath(path) {
+		return PartitionLimit{}, quota, false, true
+	}
+
+	limits = resolved.RateLimit
+	if resolved.Quota > 0 {
+		quota = resolved.Quota
+	}
+	return limits, quota, unlimited, false
+}
+
+// ResolvePolicy exposes the policy merge for apiKey/path to callers outside
+// CheckRateLimit's enforcement path (e.g. AuthMiddleware, for attaching the
+// resolved policy to the request context for observability). It is a
+// read-only view: CheckRateLimit/effectiveLimits remain the sole authority
+// for what's actually enforced. found is false when no PolicyStore is
+// installed or apiKey has no assigned policies.
+func (r *RateLimiter) ResolvePolicy(ctx context.Context, apiKey, path string) (policy ResolvedPolicy, found bool) {
+	if r.policies == nil {
+		return ResolvedPolicy{}, false
+	}
+	return r.policies.Resolve(apiKey, path)
+}
+
+// checkSlidingWindows checks the per-minute/hour/day tiers as true sliding
+// windows. It first peeks every tier (see peekWindow) without consuming
+// anything; only once all three agree there's room does it commit the
+// monthly quota increment and each tier's own atomic check-and-add, so a
+// request rejected by, say, the hour tier never leaves the minute tier (or
+// the quota) counted with nothing to show for it. When cluster-distributed
+// rate limiting is enabled, the commit phase falls back to the cluster's
+// fixed-window counters instead, since forwarding ZSET mutations across
+// peers is out of scope for the sliding-window algorithm.
+func (r *RateLimiter) checkSlidingWindows(ctx context.Context, apiKey string, quotaLimit int, limits PartitionLimit, now time.Time) (*RateLimitResult, error) {
 	windows := []struct {
 		name   string
 		limit  int
 		window time.Duration
 	}{
-		{"minute", r.config.RequestsPerMinute, time.Minute},
-		{"hour", r.config.RequestsPerHour, time.Hour},
-		{"day", r.config.RequestsPerDay, 24 * time.Hour},
+		{"minute", limits.RequestsPerMinute, time.Minute},
+		{"hour", limits.RequestsPerHour, time.Hour},
+		{"day", limits.RequestsPerDay, 24 * time.Hour},
 	}
 
 	for _, w := range windows {
-		windowKey := r.getWindowKey(now, w.window)
-		current, err := r.redis.GetRateLimit(ctx, apiKey, windowKey)
+		current, resetTime, err := r.peekWindow(ctx, apiKey, w.name, now, w.window)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get rate limit for %s window: %w", w.name, err)
+			return nil, fmt.Errorf("failed to check rate limit for %s window: %w", w.name, err)
 		}
 
 		if current >= w.limit {
-			resetTime := r.getWindowResetTime(now, w.window)
 			r.logger.Warn("Rate limit exceeded",
-				zap.String("api_key", apiKey),
-				zap.String("window", w.name),
-				zap.Int("current", current),
-				zap.Int("limit", w.limit),
-				zap.Time("reset_time", resetTime),
+				slog.String("api_key", apiKey),
+				slog.String("window", w.name),
+				slog.Int("current", current),
+				slog.Int("limit", w.limit),
+				slog.Time("reset_time", resetTime),
+			)
+			return &RateLimitResult{
+				Allowed:    false,
+				Remaining:  0,
+				ResetTime:  resetTime,
+				Limit:      w.limit,
+				Window:     w.name,
+				QuotaLimit: quotaLimit,
+			}, nil
+		}
+	}
+
+	quotaAllowed, quotaUsed, err := r.commitMonthlyQuota(ctx, apiKey, quotaLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check monthly quota: %w", err)
+	}
+	if !quotaAllowed {
+		r.logger.Warn("Monthly quota exceeded",
+			slog.String("api_key", apiKey),
+			slog.Int("quota_used", quotaUsed),
+			slog.Int("quota_limit", quotaLimit),
+		)
+		return &RateLimitResult{
+			Allowed:    false,
+			Remaining:  0,
+			ResetTime:  time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()),
+			Limit:      0,
+			Window:     "monthly",
+			QuotaUsed:  quotaUsed,
+			QuotaLimit: quotaLimit,
+		}, nil
+	}
+
+	for _, w := range windows {
+		allowed, current, resetTime, err := r.checkAndConsumeWindow(ctx, apiKey, w.name, now, w.window, w.limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check rate limit for %s window: %w", w.name, err)
+		}
+
+		if !allowed {
+			// Another request raced in between the peek above and this
+			// commit. The quota increment just above already landed and
+			// there is no rollback for it, but the window this happened in
+			// is far narrower than before: it requires a concurrent request
+			// to land in the gap between two round trips instead of always
+			// triggering whenever a later tier rejects.
+			r.logger.Warn("Rate limit exceeded on commit after passing peek",
+				slog.String("api_key", apiKey),
+				slog.String("window", w.name),
+				slog.Int("current", current),
+				slog.Int("limit", w.limit),
+				slog.Time("reset_time", resetTime),
 			)
 			return &RateLimitResult{
 				Allowed:    false,
@@ -106,7 +593,7 @@ func (r *RateLimiter) CheckRateLimit(ctx context.Context, apiKey string) (*RateL
 				Limit:      w.limit,
 				Window:     w.name,
 				QuotaUsed:  quotaUsed,
-				QuotaLimit: r.config.MonthlyQuota,
+				QuotaLimit: quotaLimit,
 			}, nil
 		}
 	}
@@ -114,52 +601,193 @@ func (r *RateLimiter) CheckRateLimit(ctx context.Context, apiKey string) (*RateL
 	// All checks passed
 	return &RateLimitResult{
 		Allowed:    true,
-		Remaining:  r.config.RequestsPerMinute, // We'll update this after incrementing
-		ResetTime:  r.getWindowResetTime(now, time.Minute),
-		Limit:      r.config.RequestsPerMinute,
+		Remaining:  limits.RequestsPerMinute,
+		ResetTime:  now.Add(time.Minute),
+		Limit:      limits.RequestsPerMinute,
 		Window:     "minute",
 		QuotaUsed:  quotaUsed,
-		QuotaLimit: r.config.MonthlyQuota,
+		QuotaLimit: quotaLimit,
 	}, nil
 }
 
-// IncrementRateLimit increments the rate limit counters for all windows
-func (r *RateLimiter) IncrementRateLimit(ctx context.Context, apiKey string) error {
-	now := time.Now()
-	
-	// Increment monthly quota
-	_, err := r.redis.IncrementMonthlyQuota(ctx, apiKey, r.config.MonthlyQuota)
+// checkTokenBucket checks and consumes a single token from apiKey's bucket,
+// atomically, as part of the check itself, and only commits the monthly
+// quota increment once the bucket has confirmed the request is allowed —
+// a rejected request never leaves the quota counted.
+func (r *RateLimiter) checkTokenBucket(ctx context.Context, apiKey string, quotaLimit int, now time.Time) (*RateLimitResult, error) {
+	cfg := r.tokenBucketConfigFor(apiKey)
+
+	result, err := r.redis.ConsumeToken(ctx, r.tokenBucketKey(apiKey), cfg.Rate, cfg.Burst, 1)
 	if err != nil {
-		return fmt.Errorf("failed to increment monthly quota: %w", err)
+		return nil, fmt.Errorf("failed to check token bucket: %w", err)
 	}
 
-	// Increment rate limit for all windows
-	windows := []struct {
-		name   string
-		limit  int
-		window time.Duration
-	}{
-		{"minute", r.config.RequestsPerMinute, time.Minute},
-		{"hour", r.config.RequestsPerHour, time.Hour},
-		{"day", r.config.RequestsPerDay, 24 * time.Hour},
+	if !result.Allowed {
+		var wait time.Duration
+		if cfg.Rate > 0 {
+			wait = time.Duration((1 - result.Tokens) / cfg.Rate * float64(time.Second))
+		}
+		r.logger.Warn("Token bucket rate limit exceeded",
+			slog.String("api_key", apiKey),
+			slog.Float64("tokens", result.Tokens),
+			slog.Float64("burst", cfg.Burst),
+		)
+		return &RateLimitResult{
+			Allowed:    false,
+			Remaining:  0,
+			ResetTime:  now.Add(wait),
+			Limit:      int(cfg.Burst),
+			Window:     "token_bucket",
+			QuotaLimit: quotaLimit,
+		}, nil
 	}
 
-	for _, w := range windows {
-		windowKey := r.getWindowKey(now, w.window)
-		current, err := r.redis.IncrementRateLimit(ctx, apiKey, windowKey, w.limit)
+	quotaAllowed, quotaUsed, err := r.commitMonthlyQuota(ctx, apiKey, quotaLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check monthly quota: %w", err)
+	}
+	if !quotaAllowed {
+		r.logger.Warn("Monthly quota exceeded",
+			slog.String("api_key", apiKey),
+			slog.Int("quota_used", quotaUsed),
+			slog.Int("quota_limit", quotaLimit),
+		)
+		return &RateLimitResult{
+			Allowed:    false,
+			Remaining:  0,
+			ResetTime:  time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()),
+			Limit:      0,
+			Window:     "monthly",
+			QuotaUsed:  quotaUsed,
+			QuotaLimit: quotaLimit,
+		}, nil
+	}
+
+	return &RateLimitResult{
+		Allowed:    true,
+		Remaining:  int(result.Tokens),
+		ResetTime:  now,
+		Limit:      int(cfg.Burst),
+		Window:     "token_bucket",
+		QuotaUsed:  quotaUsed,
+		QuotaLimit: quotaLimit,
+	}, nil
+}
+
+// checkAndConsumeWindow atomically checks apiKey's tier counter against
+// limit and, only if still under it, records the request — so the request
+// is never counted when it's rejected. Cluster mode keeps its existing
+// check-then-forward split (getCount then incrementCount), since batched
+// cluster increments are already fire-and-forget by design (see
+// incrementCount); a direct Redis sliding-window ZSET check-and-add is
+// atomic in a single round trip.
+func (r *RateLimiter) checkAndConsumeWindow(ctx context.Context, apiKey, tier string, now time.Time, window time.Duration, limit int) (allowed bool, current int, resetTime time.Time, err error) {
+	if r.cluster != nil {
+		windowKey := r.getWindowKey(now, window)
+		resetTime = r.getWindowResetTime(now, window)
+
+		current, err = r.getCount(ctx, apiKey, windowKey)
 		if err != nil {
-			return fmt.Errorf("failed to increment rate limit for %s window: %w", w.name, err)
+			return false, 0, resetTime, err
+		}
+		if current >= limit {
+			return false, current, resetTime, nil
 		}
 
-		r.logger.Debug("Rate limit incremented",
-			zap.String("api_key", apiKey),
-			zap.String("window", w.name),
-			zap.Int("current", current),
-			zap.Int("limit", w.limit),
-		)
+		current, err = r.incrementCount(ctx, apiKey, windowKey, limit)
+		return true, current, resetTime, err
+	}
+
+	allowed, current, oldest, err := r.redis.CheckAndAddSlidingWindow(ctx, r.slidingWindowKey(apiKey, tier), now, window, limit)
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+	return allowed, current, oldest.Add(window), nil
+}
+
+// peekWindow reports apiKey's current count and reset time for tier, without
+// consuming a request, for GetRateLimitInfo's reporting endpoint and for the
+// peek phase of checkSlidingWindows. It routes
+// through the cluster's fixed-window counters when one is configured, or a
+// direct Redis sliding-window ZSET otherwise.
+func (r *RateLimiter) peekWindow(ctx context.Context, apiKey, tier string, now time.Time, window time.Duration) (current int, resetTime time.Time, err error) {
+	if r.cluster != nil {
+		windowKey := r.getWindowKey(now, window)
+		current, err = r.getCount(ctx, apiKey, windowKey)
+		return current, r.getWindowResetTime(now, window), err
+	}
+
+	current, oldest, err := r.redis.PeekSlidingWindow(ctx, r.slidingWindowKey(apiKey, tier), now, window)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return current, oldest.Add(window), nil
+}
+
+func (r *RateLimiter) slidingWindowKey(apiKey, tier string) string {
+	return fmt.Sprintf("rate:sliding:%s:%s", apiKey, tier)
+}
+
+func (r *RateLimiter) tokenBucketKey(apiKey string) string {
+	return fmt.Sprintf("rate:bucket:%s", apiKey)
+}
+
+// SetDescriptorConfig installs the hierarchical descriptor rules used by
+// ShouldRateLimit. Without it, ShouldRateLimit falls back to the gateway's
+// global per-minute limit for every descriptor.
+func (r *RateLimiter) SetDescriptorConfig(cfg *DomainConfig) {
+	r.descriptorConfig = cfg
+}
+
+// ShouldRateLimit implements the Envoy RateLimitService contract on top of
+// the same Redis counters as CheckRateLimit: each
+// descriptor is matched against the configured rules (falling back to the
+// gateway's global per-minute limit), counted in its own window, and
+// incremented if it's within limit.
+func (r *RateLimiter) ShouldRateLimit(ctx context.Context, domain string, descriptors [][]KV) ([]DescriptorStatus, OverallStatus, error) {
+	statuses := make([]DescriptorStatus, 0, len(descriptors))
+	overall := StatusOK
+
+	for _, descriptor := range descriptors {
+		limit := r.config.RequestsPerMinute
+		window := time.Minute
+		if r.descriptorConfig != nil {
+			if rule := r.descriptorConfig.match(descriptor); rule != nil {
+				limit = rule.RateLimit.RequestsPerUnit
+				window = rule.RateLimit.Duration()
+			}
+		}
+
+		key := descriptorKey(domain, descriptor)
+		windowKey := r.getWindowKey(time.Now(), window)
+		current, err := r.redis.GetRateLimit(ctx, key, windowKey)
+		if err != nil {
+			return nil, StatusUnknown, fmt.Errorf("failed to get descriptor rate limit: %w", err)
+		}
+
+		status := DescriptorStatus{
+			CurrentLimit:       limit,
+			LimitRemaining:     limit - current,
+			DurationUntilReset: int64(window.Seconds()),
+		}
+
+		if current >= limit {
+			status.Code = StatusOverLimit
+			status.LimitRemaining = 0
+			overall = StatusOverLimit
+			statuses = append(statuses, status)
+			continue
+		}
+
+		status.Code = StatusOK
+		if _, err := r.redis.IncrementRateLimit(ctx, key, windowKey, limit); err != nil {
+			return nil, StatusUnknown, fmt.Errorf("failed to increment descriptor rate limit: %w", err)
+		}
+		status.LimitRemaining--
+		statuses = append(statuses, status)
 	}
 
-	return nil
+	return statuses, overall, nil
 }
 
 // getWindowKey generates a window key based on the current time and window duration
@@ -220,8 +848,7 @@ func (r *RateLimiter) GetRateLimitInfo(ctx context.Context, apiKey string) (map[
 	}
 
 	for _, w := range windows {
-		windowKey := r.getWindowKey(now, w.window)
-		current, err := r.redis.GetRateLimit(ctx, apiKey, windowKey)
+		current, resetTime, err := r.peekWindow(ctx, apiKey, w.name, now, w.window)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get rate limit for %s window: %w", w.name, err)
 		}
@@ -230,7 +857,7 @@ func (r *RateLimiter) GetRateLimitInfo(ctx context.Context, apiKey string) (map[
 			"current":    current,
 			"limit":      w.limit,
 			"remaining":  w.limit - current,
-			"reset_time": r.getWindowResetTime(now, w.window),
+			"reset_time": resetTime,
 		}
 	}
 
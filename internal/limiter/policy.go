@@ -0,0 +1,243 @@
+package limiter
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ACL governs whether a policy permits or explicitly denies a matched API
+// partition. Denial is fail-closed: it overrides any other policy's
+// permissive rate for the same API when a key has multiple policies.
+type ACL int
+
+const (
+	// ACLAllow permits the matched API, subject to its rate limit.
+	ACLAllow ACL = iota
+	// ACLDeny blocks the matched API outright, regardless of rate limit.
+	ACLDeny
+)
+
+// PartitionLimit holds the per-minute/hour/day tiering enforced for one
+// partition (either a policy's global fallback or one PerAPI entry).
+type PartitionLimit struct {
+	RequestsPerMinute int
+	RequestsPerHour   int
+	RequestsPerDay    int
+}
+
+// APIPartition is one entry in Policy.PerAPI: an independent rate limit
+// (and optional deny) for requests whose path matches Pattern.
+type APIPartition struct {
+	Pattern   string
+	RateLimit PartitionLimit
+	ACL       ACL
+}
+
+// Policy is a named bundle of quota/rate-limit/ACL partitions that can be
+// assigned to one or more API keys. PerAPI lets specific routes (or
+// upstream API IDs) carry independent limits from RateLimit, the policy's
+// global fallback, so e.g. /v1/search can be limited separately from
+// /v1/embeddings.
+type Policy struct {
+	Name      string
+	Quota     int
+	RateLimit PartitionLimit
+	PerAPI    map[string]APIPartition
+	// AllowedScopes is a path-prefix allowlist for this policy's tier; a key
+	// may only call paths matching one of them. Empty means unrestricted.
+	AllowedScopes []string
+	// BypassRateLimit opts a key assigned this policy out of rate limiting
+	// entirely, for trusted internal callers that shouldn't be throttled.
+	BypassRateLimit bool
+}
+
+// match returns the PerAPI partition whose pattern is the longest prefix of
+// path, or nil if none match. Patterns are matched by prefix rather than an
+// exact route pattern since the gateway proxies through a single catch-all
+// Gin route ("/proxy/*path") — the upstream API identity lives in the
+// incoming path, not in Gin's own routing.
+func (p *Policy) match(path string) *APIPartition {
+	var best *APIPartition
+	bestLen := -1
+
+	for pattern := range p.PerAPI {
+		if strings.HasPrefix(path, pattern) && len(pattern) > bestLen {
+			partition := p.PerAPI[pattern]
+			best = &partition
+			bestLen = len(pattern)
+		}
+	}
+
+	return best
+}
+
+// ResolvedPolicy is the effective partition limit for one API key against
+// one request path, after merging every policy assigned to that key.
+type ResolvedPolicy struct {
+	RateLimit PartitionLimit
+	Quota     int
+	Source    string
+	Denied    bool
+	// AllowedScopes is the union of every assigned policy's AllowedScopes,
+	// unless any one of them is unrestricted (empty), in which case the
+	// merged result is unrestricted too — the same "most permissive wins"
+	// rule RateLimit/Quota already follow. Nil/empty means unrestricted.
+	AllowedScopes []string
+	// Bypass is true when any assigned policy sets BypassRateLimit.
+	Bypass bool
+
+	hasLimit bool
+}
+
+// AllowsPath reports whether path is permitted under AllowedScopes, matched
+// by prefix like APIPartition.Pattern. A nil/empty AllowedScopes means every
+// path is allowed.
+func (r ResolvedPolicy) AllowsPath(path string) bool {
+	if len(r.AllowedScopes) == 0 {
+		return true
+	}
+	for _, scope := range r.AllowedScopes {
+		if strings.HasPrefix(path, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyStore resolves an API key to its assigned policies and merges them
+// into a single effective decision for a given request path.
+type PolicyStore struct {
+	mu       sync.RWMutex
+	policies map[string]*Policy
+	keys     map[string][]string // apiKey -> assigned policy names
+}
+
+// NewPolicyStore creates an empty policy store.
+func NewPolicyStore() *PolicyStore {
+	return &PolicyStore{
+		policies: make(map[string]*Policy),
+		keys:     make(map[string][]string),
+	}
+}
+
+// AddPolicy registers (or replaces) a named policy.
+func (s *PolicyStore) AddPolicy(policy *Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies[policy.Name] = policy
+}
+
+// AssignPolicy associates an API key with a policy by name. A key may be
+// assigned more than one policy.
+func (s *PolicyStore) AssignPolicy(apiKey, policyName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[apiKey] = append(s.keys[apiKey], policyName)
+}
+
+// Resolve merges every policy assigned to apiKey for the given request path
+// into one effective partition: the most permissive RequestsPerMinute
+// across policies (falling back to each policy's global RateLimit when none
+// of its PerAPI patterns match), unless any policy's matched partition
+// explicitly denies the API, in which case the key fails closed for that
+// path. AllowedScopes is the union of every policy's scopes unless one of
+// them is unrestricted, and Bypass is set if any policy opts out of rate
+// limiting entirely — both follow the same most-permissive-wins rule as the
+// rate/quota merge. The second return value is false when apiKey has no
+// assigned policies, so callers can fall back to the gateway's global
+// limits.
+func (s *PolicyStore) Resolve(apiKey, path string) (ResolvedPolicy, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := s.keys[apiKey]
+	if len(names) == 0 {
+		return ResolvedPolicy{}, false
+	}
+
+	var resolved ResolvedPolicy
+	found := false
+	scopeSet := make(map[string]bool)
+	unrestricted := false
+
+	for _, name := range names {
+		policy, ok := s.policies[name]
+		if !ok {
+			continue
+		}
+		found = true
+
+		partition := policy.RateLimit
+		source := "global"
+		if match := policy.match(path); match != nil {
+			if match.ACL == ACLDeny {
+				return ResolvedPolicy{Denied: true, Source: match.Pattern}, true
+			}
+			partition = match.RateLimit
+			source = match.Pattern
+		}
+
+		if !resolved.hasLimit || partition.RequestsPerMinute > resolved.RateLimit.RequestsPerMinute {
+			resolved.RateLimit = partition
+			resolved.Source = source
+			resolved.hasLimit = true
+		}
+
+		if policy.Quota > resolved.Quota {
+			resolved.Quota = policy.Quota
+		}
+
+		if policy.BypassRateLimit {
+			resolved.Bypass = true
+		}
+
+		if len(policy.AllowedScopes) == 0 {
+			unrestricted = true
+		} else {
+			for _, scope := range policy.AllowedScopes {
+				scopeSet[scope] = true
+			}
+		}
+	}
+
+	if !unrestricted {
+		resolved.AllowedScopes = make([]string, 0, len(scopeSet))
+		for scope := range scopeSet {
+			resolved.AllowedScopes = append(resolved.AllowedScopes, scope)
+		}
+		sort.Strings(resolved.AllowedScopes)
+	}
+
+	return resolved, found
+}
+
+// ReplaceFrom atomically swaps this store's policies and key assignments
+// for other's, for hot-reloading a policy config file without restarting
+// the gateway (see WatchPolicyConfig). In-flight Resolve calls either see
+// the old or the new state, never a partial mix.
+func (s *PolicyStore) ReplaceFrom(other *PolicyStore) {
+	other.mu.RLock()
+	policies := other.policies
+	keys := other.keys
+	other.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies = policies
+	s.keys = keys
+}
+
+// BypassRateLimit reports whether any policy assigned to apiKey sets
+// BypassRateLimit, without needing a request path to resolve against.
+func (s *PolicyStore) BypassRateLimit(apiKey string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, name := range s.keys[apiKey] {
+		if policy, ok := s.policies[name]; ok && policy.BypassRateLimit {
+			return true
+		}
+	}
+	return false
+}
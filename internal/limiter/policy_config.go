@@ -0,0 +1,133 @@
+package limiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// policyConfigPartition is the YAML shape of an APIPartition.
+type policyConfigPartition struct {
+	Pattern           string `yaml:"pattern" json:"pattern"`
+	RequestsPerMinute int    `yaml:"requests_per_minute" json:"requests_per_minute"`
+	RequestsPerHour   int    `yaml:"requests_per_hour" json:"requests_per_hour"`
+	RequestsPerDay    int    `yaml:"requests_per_day" json:"requests_per_day"`
+	Deny              bool   `yaml:"deny,omitempty" json:"deny,omitempty"`
+}
+
+// policyConfigEntry is the YAML shape of one named Policy, plus the API
+// keys it should be assigned to.
+type policyConfigEntry struct {
+	Name              string                  `yaml:"name" json:"name"`
+	Quota             int                     `yaml:"quota,omitempty" json:"quota,omitempty"`
+	RequestsPerMinute int                     `yaml:"requests_per_minute,omitempty" json:"requests_per_minute,omitempty"`
+	RequestsPerHour   int                     `yaml:"requests_per_hour,omitempty" json:"requests_per_hour,omitempty"`
+	RequestsPerDay    int                     `yaml:"requests_per_day,omitempty" json:"requests_per_day,omitempty"`
+	PerAPI            []policyConfigPartition `yaml:"per_api,omitempty" json:"per_api,omitempty"`
+	APIKeys           []string                `yaml:"api_keys,omitempty" json:"api_keys,omitempty"`
+	// AllowedScopes and BypassRateLimit map directly onto the matching
+	// Policy fields.
+	AllowedScopes   []string `yaml:"allowed_scopes,omitempty" json:"allowed_scopes,omitempty"`
+	BypassRateLimit bool     `yaml:"bypass_rate_limit,omitempty" json:"bypass_rate_limit,omitempty"`
+}
+
+// policyConfigFile is the top-level document loaded by LoadPolicyConfig: a
+// flat list of named policies, each carrying its own assigned API keys.
+type policyConfigFile struct {
+	Policies []policyConfigEntry `yaml:"policies" json:"policies"`
+}
+
+// LoadPolicyConfig reads named policies and their API key assignments from
+// a file on disk and returns a populated PolicyStore, in the same
+// config-file style as LoadDescriptorConfig. The format is chosen by file
+// extension: ".json" is parsed as JSON, anything else as YAML.
+func LoadPolicyConfig(path string) (*PolicyStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy config %s: %w", path, err)
+	}
+
+	var cfg policyConfigFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse policy config %s: %w", path, err)
+		}
+	} else if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse policy config %s: %w", path, err)
+	}
+
+	store := NewPolicyStore()
+	for _, entry := range cfg.Policies {
+		policy := &Policy{
+			Name:  entry.Name,
+			Quota: entry.Quota,
+			RateLimit: PartitionLimit{
+				RequestsPerMinute: entry.RequestsPerMinute,
+				RequestsPerHour:   entry.RequestsPerHour,
+				RequestsPerDay:    entry.RequestsPerDay,
+			},
+			AllowedScopes:   entry.AllowedScopes,
+			BypassRateLimit: entry.BypassRateLimit,
+		}
+
+		if len(entry.PerAPI) > 0 {
+			policy.PerAPI = make(map[string]APIPartition, len(entry.PerAPI))
+			for _, p := range entry.PerAPI {
+				acl := ACLAllow
+				if p.Deny {
+					acl = ACLDeny
+				}
+				policy.PerAPI[p.Pattern] = APIPartition{
+					Pattern: p.Pattern,
+					RateLimit: PartitionLimit{
+						RequestsPerMinute: p.RequestsPerMinute,
+						RequestsPerHour:   p.RequestsPerHour,
+						RequestsPerDay:    p.RequestsPerDay,
+					},
+					ACL: acl,
+				}
+			}
+		}
+
+		store.AddPolicy(policy)
+		for _, apiKey := range entry.APIKeys {
+			store.AssignPolicy(apiKey, entry.Name)
+		}
+	}
+
+	return store, nil
+}
+
+// WatchPolicyConfig reloads path into store on a ticker until ctx is
+// cancelled, so policy/key-assignment changes (e.g. a new tier or a revoked
+// bypass flag) take effect without a gateway restart. Reload failures are
+// logged and skipped, leaving the previous policies in effect.
+func WatchPolicyConfig(ctx context.Context, store *PolicyStore, path string, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := LoadPolicyConfig(path)
+			if err != nil {
+				logger.Error("Failed to reload policy config",
+					slog.String("path", path),
+					slog.Any("error", err),
+				)
+				continue
+			}
+			store.ReplaceFrom(reloaded)
+			logger.Info("Reloaded policy config", slog.String("path", path))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package limiter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyStore_Resolve_MostPermissiveRateLimitWins(t *testing.T) {
+	store := NewPolicyStore()
+	store.AddPolicy(&Policy{
+		Name:      "tight",
+		RateLimit: PartitionLimit{RequestsPerMinute: 10},
+	})
+	store.AddPolicy(&Policy{
+		Name:      "loose",
+		RateLimit: PartitionLimit{RequestsPerMinute: 100},
+	})
+	store.AssignPolicy("api-key", "tight")
+	store.AssignPolicy("api-key", "loose")
+
+	resolved, found := store.Resolve("api-key", "/v1/search")
+
+	assert.True(t, found)
+	assert.Equal(t, 100, resolved.RateLimit.RequestsPerMinute, "the more permissive of the two assigned policies should win")
+	assert.Equal(t, "global", resolved.Source)
+}
+
+func TestPolicyStore_Resolve_HighestQuotaWins(t *testing.T) {
+	store := NewPolicyStore()
+	store.AddPolicy(&Policy{Name: "low", Quota: 1000})
+	store.AddPolicy(&Policy{Name: "high", Quota: 50000})
+	store.AssignPolicy("api-key", "low")
+	store.AssignPolicy("api-key", "high")
+
+	resolved, found := store.Resolve("api-key", "/v1/search")
+
+	assert.True(t, found)
+	assert.Equal(t, 50000, resolved.Quota)
+}
+
+func TestPolicyStore_Resolve_PerAPIPartitionOverridesGlobal(t *testing.T) {
+	store := NewPolicyStore()
+	store.AddPolicy(&Policy{
+		Name:      "tiered",
+		RateLimit: PartitionLimit{RequestsPerMinute: 10},
+		PerAPI: map[string]APIPartition{
+			"/v1/search": {
+				Pattern:   "/v1/search",
+				RateLimit: PartitionLimit{RequestsPerMinute: 5},
+			},
+		},
+	})
+	store.AssignPolicy("api-key", "tiered")
+
+	resolved, found := store.Resolve("api-key", "/v1/search/embeddings")
+
+	assert.True(t, found)
+	assert.Equal(t, 5, resolved.RateLimit.RequestsPerMinute)
+	assert.Equal(t, "/v1/search", resolved.Source)
+}
+
+func TestPolicyStore_Resolve_DenyShortCircuitsEvenWithAPermissivePolicy(t *testing.T) {
+	store := NewPolicyStore()
+	store.AddPolicy(&Policy{
+		Name: "denied",
+		PerAPI: map[string]APIPartition{
+			"/v1/admin": {Pattern: "/v1/admin", ACL: ACLDeny},
+		},
+	})
+	store.AddPolicy(&Policy{
+		Name:      "permissive",
+		RateLimit: PartitionLimit{RequestsPerMinute: 1000},
+	})
+	store.AssignPolicy("api-key", "denied")
+	store.AssignPolicy("api-key", "permissive")
+
+	resolved, found := store.Resolve("api-key", "/v1/admin/users")
+
+	assert.True(t, found)
+	assert.True(t, resolved.Denied, "a matched ACLDeny partition must fail closed regardless of other assigned policies")
+}
+
+func TestPolicyStore_Resolve_ScopesAreUnrestrictedIfAnyPolicyIsUnrestricted(t *testing.T) {
+	store := NewPolicyStore()
+	store.AddPolicy(&Policy{Name: "scoped", AllowedScopes: []string{"/v1/search"}})
+	store.AddPolicy(&Policy{Name: "unrestricted"}) // empty AllowedScopes
+	store.AssignPolicy("api-key", "scoped")
+	store.AssignPolicy("api-key", "unrestricted")
+
+	resolved, found := store.Resolve("api-key", "/v1/search")
+
+	assert.True(t, found)
+	assert.Empty(t, resolved.AllowedScopes)
+	assert.True(t, resolved.AllowsPath("/anything"))
+}
+
+func TestPolicyStore_Resolve_NoAssignedPoliciesReturnsNotFound(t *testing.T) {
+	store := NewPolicyStore()
+	_, found := store.Resolve("unknown-key", "/v1/search")
+	assert.False(t, found)
+}
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DedupHandler wraps a slog.Handler and collapses repeated records with the
+// same level, message, and key attributes into a single record carrying a
+// "count" attribute, within a configurable window. This matters because
+// per-request rate-limit rejection logs would otherwise spam the log stream
+// when a single misbehaving client hits the limiter thousands of times per
+// second.
+type DedupHandler struct {
+	next   slog.Handler
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+type dedupEntry struct {
+	record slog.Record
+	count  int
+	timer  *time.Timer
+}
+
+// NewDedupHandler wraps next so that identical records within window collapse:
+// the first is passed through at once, and if more identical records arrive
+// before window elapses, one more copy carrying "count" (the total seen,
+// first included) is emitted when it does.
+func NewDedupHandler(next slog.Handler, window time.Duration) *DedupHandler {
+	return &DedupHandler{
+		next:    next,
+		window:  window,
+		entries: make(map[string]*dedupEntry),
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *DedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *DedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DedupHandler{next: h.next.WithAttrs(attrs), window: h.window, entries: make(map[string]*dedupEntry)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *DedupHandler) WithGroup(name string) slog.Handler {
+	return &DedupHandler{next: h.next.WithGroup(name), window: h.window, entries: make(map[string]*dedupEntry)}
+}
+
+// Handle implements slog.Handler.
+func (h *DedupHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.window <= 0 {
+		return h.next.Handle(ctx, record)
+	}
+
+	key := dedupKey(record)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry, ok := h.entries[key]; ok {
+		entry.count++
+		return nil
+	}
+
+	clone := record.Clone()
+	entry := &dedupEntry{record: clone, count: 1}
+	entry.timer = time.AfterFunc(h.window, func() { h.flush(ctx, key) })
+	h.entries[key] = entry
+
+	return h.next.Handle(ctx, record)
+}
+
+func (h *DedupHandler) flush(ctx context.Context, key string) {
+	h.mu.Lock()
+	entry, ok := h.entries[key]
+	if ok {
+		delete(h.entries, key)
+	}
+	h.mu.Unlock()
+
+	if !ok || entry.count <= 1 {
+		return
+	}
+
+	record := entry.record.Clone()
+	record.Add(slog.Int("count", entry.count))
+	_ = h.next.Handle(ctx, record)
+}
+
+// dedupKey identifies a record by its level, message, and attributes so that
+// only truly identical records collapse.
+func dedupKey(record slog.Record) string {
+	key := record.Level.String() + "|" + record.Message
+	record.Attrs(func(a slog.Attr) bool {
+		key += "|" + a.Key + "=" + a.Value.String()
+		return true
+	})
+	return key
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricType is the Prometheus metric kind a MetricDef describes.
+type MetricType string
+
+const (
+	CounterMetric   MetricType = "counter"
+	HistogramMetric MetricType = "histogram"
+	GaugeMetric     MetricType = "gauge"
+)
+
+// MetricDef fully describes one metric: its name, help text, label set, and
+// (for histograms) bucket boundaries. Collector is built from a table of
+// these so the metrics surface has a single source of truth that can also
+// be dumped to JSON for diffing across releases.
+type MetricDef struct {
+	Name    string     `json:"name"`
+	Help    string     `json:"help"`
+	Labels  []string   `json:"labels"`
+	Type    MetricType `json:"type"`
+	Buckets []float64  `json:"buckets,omitempty"`
+}
+
+// metricDefs is the single table every gateway metric is declared in.
+var metricDefs = []MetricDef{
+	{
+		Name:   "gateway_requests_total",
+		Help:   "Total number of requests processed",
+		Labels: []string{"method", "path", "status_code", "api_key"},
+		Type:   CounterMetric,
+	},
+	{
+		Name:    "gateway_request_duration_seconds",
+		Help:    "Request duration in seconds",
+		Labels:  []string{"method", "path", "status_code"},
+		Type:    HistogramMetric,
+		Buckets: prometheus.DefBuckets,
+	},
+	{
+		Name:    "gateway_request_size_bytes",
+		Help:    "Request size in bytes",
+		Labels:  []string{"method", "path"},
+		Type:    HistogramMetric,
+		Buckets: prometheus.ExponentialBuckets(100, 10, 8),
+	},
+	{
+		Name:    "gateway_response_size_bytes",
+		Help:    "Response size in bytes",
+		Labels:  []string{"method", "path", "status_code"},
+		Type:    HistogramMetric,
+		Buckets: prometheus.ExponentialBuckets(100, 10, 8),
+	},
+	{
+		Name:   "gateway_rate_limit_exceeded_total",
+		Help:   "Total number of rate limit violations",
+		Labels: []string{"api_key", "window"},
+		Type:   CounterMetric,
+	},
+	{
+		Name:   "gateway_upstream_errors_total",
+		Help:   "Total number of upstream service errors",
+		Labels: []string{"api_key", "error_type"},
+		Type:   CounterMetric,
+	},
+	{
+		Name:   "gateway_requests_bypassed_total",
+		Help:   "Total number of requests that skipped rate limiting via a bypass mechanism",
+		Labels: []string{"reason"},
+		Type:   CounterMetric,
+	},
+	{
+		Name:   "gateway_inflight_requests",
+		Help:   "Current number of requests admitted into a concurrency pool but not yet completed",
+		Labels: []string{"pool"},
+		Type:   GaugeMetric,
+	},
+}
+
+// Collector implements prometheus.Collector over metricDefs, registering
+// every metric through Describe/Collect instead of hand-rolled
+// MustRegister calls. Unlike prometheus.MustRegister, which panics on a
+// duplicate registration against the global default registry, a Collector is
+// self-contained: callers register it against their own *prometheus.Registry,
+// so building a second Metrics instance (as tests often do) is safe.
+type Collector struct {
+	defs       []MetricDef
+	counters   map[string]*prometheus.CounterVec
+	histograms map[string]*prometheus.HistogramVec
+	gauges     map[string]*prometheus.GaugeVec
+}
+
+// NewCollector builds the counter/histogram/gauge vectors described by defs.
+func NewCollector(defs []MetricDef) *Collector {
+	c := &Collector{
+		defs:       defs,
+		counters:   make(map[string]*prometheus.CounterVec),
+		histograms: make(map[string]*prometheus.HistogramVec),
+		gauges:     make(map[string]*prometheus.GaugeVec),
+	}
+
+	for _, def := range defs {
+		switch def.Type {
+		case HistogramMetric:
+			c.histograms[def.Name] = prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{Name: def.Name, Help: def.Help, Buckets: def.Buckets},
+				def.Labels,
+			)
+		case GaugeMetric:
+			c.gauges[def.Name] = prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{Name: def.Name, Help: def.Help},
+				def.Labels,
+			)
+		default:
+			c.counters[def.Name] = prometheus.NewCounterVec(
+				prometheus.CounterOpts{Name: def.Name, Help: def.Help},
+				def.Labels,
+			)
+		}
+	}
+
+	return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, cv := range c.counters {
+		cv.Describe(ch)
+	}
+	for _, hv := range c.histograms {
+		hv.Describe(ch)
+	}
+	for _, gv := range c.gauges {
+		gv.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, cv := range c.counters {
+		cv.Collect(ch)
+	}
+	for _, hv := range c.histograms {
+		hv.Collect(ch)
+	}
+	for _, gv := range c.gauges {
+		gv.Collect(ch)
+	}
+}
+
+// Counter returns the named CounterVec, or nil if no such counter is in the table.
+func (c *Collector) Counter(name string) *prometheus.CounterVec {
+	return c.counters[name]
+}
+
+// Histogram returns the named HistogramVec, or nil if no such histogram is in the table.
+func (c *Collector) Histogram(name string) *prometheus.HistogramVec {
+	return c.histograms[name]
+}
+
+// Gauge returns the named GaugeVec, or nil if no such gauge is in the table.
+func (c *Collector) Gauge(name string) *prometheus.GaugeVec {
+	return c.gauges[name]
+}
+
+// Defs returns the metric table the collector was built from, e.g. for
+// `flowguard dump-metrics` to walk and serialize.
+func (c *Collector) Defs() []MetricDef {
+	return c.defs
+}
@@ -1,95 +1,36 @@
 package metrics
 
 import (
+	"log/slog"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"go.uber.org/zap"
 )
 
 // Metrics holds all Prometheus metrics
 type Metrics struct {
-	requestCounter   *prometheus.CounterVec
-	requestDuration  *prometheus.HistogramVec
-	requestSize      *prometheus.HistogramVec
-	responseSize     *prometheus.HistogramVec
-	rateLimitCounter *prometheus.CounterVec
-	upstreamErrors   *prometheus.CounterVec
-	logger           *zap.Logger
+	registry  *prometheus.Registry
+	collector *Collector
+	logger    *slog.Logger
 }
 
-// NewMetrics creates a new metrics instance
-func NewMetrics(logger *zap.Logger) *Metrics {
-	requestCounter := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "gateway_requests_total",
-			Help: "Total number of requests processed",
-		},
-		[]string{"method", "path", "status_code", "api_key"},
-	)
-
-	requestDuration := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "gateway_request_duration_seconds",
-			Help:    "Request duration in seconds",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"method", "path", "status_code"},
-	)
-
-	requestSize := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "gateway_request_size_bytes",
-			Help:    "Request size in bytes",
-			Buckets: prometheus.ExponentialBuckets(100, 10, 8),
-		},
-		[]string{"method", "path"},
-	)
-
-	responseSize := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "gateway_response_size_bytes",
-			Help:    "Response size in bytes",
-			Buckets: prometheus.ExponentialBuckets(100, 10, 8),
-		},
-		[]string{"method", "path", "status_code"},
-	)
-
-	rateLimitCounter := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "gateway_rate_limit_exceeded_total",
-			Help: "Total number of rate limit violations",
-		},
-		[]string{"api_key", "window"},
-	)
-
-	upstreamErrors := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "gateway_upstream_errors_total",
-			Help: "Total number of upstream service errors",
-		},
-		[]string{"api_key", "error_type"},
-	)
+// NewMetrics creates a new metrics instance, registered against its own
+// prometheus.Registry rather than the global default one. This makes
+// NewMetrics safe to call more than once (e.g. once per test) since each
+// instance's Collector only ever registers against its own registry.
+func NewMetrics(logger *slog.Logger) *Metrics {
+	collector := NewCollector(metricDefs)
 
-	// Register metrics
-	prometheus.MustRegister(requestCounter)
-	prometheus.MustRegister(requestDuration)
-	prometheus.MustRegister(requestSize)
-	prometheus.MustRegister(responseSize)
-	prometheus.MustRegister(rateLimitCounter)
-	prometheus.MustRegister(upstreamErrors)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
 
 	return &Metrics{
-		requestCounter:   requestCounter,
-		requestDuration:  requestDuration,
-		requestSize:      requestSize,
-		responseSize:     responseSize,
-		rateLimitCounter: rateLimitCounter,
-		upstreamErrors:   upstreamErrors,
-		logger:           logger,
+		registry:  registry,
+		collector: collector,
+		logger:    logger,
 	}
 }
 
@@ -98,53 +39,75 @@ func (m *Metrics) RecordRequest(method, path, apiKey string, statusCode int, dur
 	statusCodeStr := strconv.Itoa(statusCode)
 	maskedAPIKey := maskAPIKey(apiKey)
 
-	m.requestCounter.WithLabelValues(method, path, statusCodeStr, maskedAPIKey).Inc()
-	m.requestDuration.WithLabelValues(method, path, statusCodeStr).Observe(duration.Seconds())
-	
+	m.collector.Counter("gateway_requests_total").WithLabelValues(method, path, statusCodeStr, maskedAPIKey).Inc()
+	m.collector.Histogram("gateway_request_duration_seconds").WithLabelValues(method, path, statusCodeStr).Observe(duration.Seconds())
+
 	if requestSize > 0 {
-		m.requestSize.WithLabelValues(method, path).Observe(float64(requestSize))
+		m.collector.Histogram("gateway_request_size_bytes").WithLabelValues(method, path).Observe(float64(requestSize))
 	}
-	
+
 	if responseSize > 0 {
-		m.responseSize.WithLabelValues(method, path, statusCodeStr).Observe(float64(responseSize))
+		m.collector.Histogram("gateway_response_size_bytes").WithLabelValues(method, path, statusCodeStr).Observe(float64(responseSize))
 	}
 
 	m.logger.Debug("Request metric recorded",
-		zap.String("method", method),
-		zap.String("path", path),
-		zap.String("api_key", maskedAPIKey),
-		zap.Int("status_code", statusCode),
-		zap.Duration("duration", duration),
-		zap.Int("request_size", requestSize),
-		zap.Int("response_size", responseSize),
+		slog.String("method", method),
+		slog.String("path", path),
+		slog.String("api_key", maskedAPIKey),
+		slog.Int("status_code", statusCode),
+		slog.Duration("duration", duration),
+		slog.Int("request_size", requestSize),
+		slog.Int("response_size", responseSize),
 	)
 }
 
 // RecordRateLimitExceeded records a rate limit violation
 func (m *Metrics) RecordRateLimitExceeded(apiKey, window string) {
 	maskedAPIKey := maskAPIKey(apiKey)
-	m.rateLimitCounter.WithLabelValues(maskedAPIKey, window).Inc()
+	m.collector.Counter("gateway_rate_limit_exceeded_total").WithLabelValues(maskedAPIKey, window).Inc()
 
 	m.logger.Debug("Rate limit metric recorded",
-		zap.String("api_key", maskedAPIKey),
-		zap.String("window", window),
+		slog.String("api_key", maskedAPIKey),
+		slog.String("window", window),
 	)
 }
 
+// RecordBypass records a request that skipped rate limiting via a bypass
+// mechanism ("config_key", "cidr", "dynamic_key", or "policy" — see
+// limiter.RateLimiter.isBypassed).
+func (m *Metrics) RecordBypass(reason string) {
+	m.collector.Counter("gateway_requests_bypassed_total").WithLabelValues(reason).Inc()
+
+	m.logger.Debug("Bypass metric recorded", slog.String("reason", reason))
+}
+
+// SetInFlight reports the current number of requests admitted into the
+// named concurrency pool (see middleware.ConcurrencyMiddleware) but not yet
+// completed.
+func (m *Metrics) SetInFlight(pool string, count int64) {
+	m.collector.Gauge("gateway_inflight_requests").WithLabelValues(pool).Set(float64(count))
+}
+
 // RecordUpstreamError records an upstream service error
 func (m *Metrics) RecordUpstreamError(apiKey, errorType string) {
 	maskedAPIKey := maskAPIKey(apiKey)
-	m.upstreamErrors.WithLabelValues(maskedAPIKey, errorType).Inc()
+	m.collector.Counter("gateway_upstream_errors_total").WithLabelValues(maskedAPIKey, errorType).Inc()
 
 	m.logger.Debug("Upstream error metric recorded",
-		zap.String("api_key", maskedAPIKey),
-		zap.String("error_type", errorType),
+		slog.String("api_key", maskedAPIKey),
+		slog.String("error_type", errorType),
 	)
 }
 
 // MetricsHandler returns the Prometheus metrics handler
 func (m *Metrics) MetricsHandler() gin.HandlerFunc {
-	return gin.WrapH(promhttp.Handler())
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Defs exposes the metric table backing this instance, e.g. for the
+// `flowguard dump-metrics` CLI subcommand.
+func (m *Metrics) Defs() []MetricDef {
+	return m.collector.Defs()
 }
 
 // maskAPIKey masks the API key for metrics (shows only first 4 and last 4 characters)
@@ -153,4 +116,4 @@ func maskAPIKey(apiKey string) string {
 		return "***"
 	}
 	return apiKey[:4] + "..." + apiKey[len(apiKey)-4:]
-} 
\ No newline at end of file
+}
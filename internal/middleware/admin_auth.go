@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader carries the shared secret required to reach any
+// /admin/* route. This is intentionally a separate credential from
+// APIKeyHeader: a regular API key (even a leaked or brute-forced one) must
+// never be sufficient to mint clients, bypass rate limiting, or force a
+// backend's circuit breaker open.
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminAuthMiddleware gates the /admin/* route groups behind a shared
+// secret, independent of AuthMiddleware.Authenticate.
+type AdminAuthMiddleware struct {
+	token  string
+	logger *slog.Logger
+}
+
+// NewAdminAuthMiddleware creates an admin auth middleware checked against
+// token. An empty token disables every /admin/* route (they 404) rather
+// than leaving them reachable with no credential.
+func NewAdminAuthMiddleware(token string, logger *slog.Logger) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{
+		token:  token,
+		logger: logger,
+	}
+}
+
+// Enabled reports whether an admin token is configured. Callers should skip
+// registering /admin/* routes entirely when this is false, so the admin
+// surface doesn't exist on the router rather than existing but always
+// rejecting.
+func (a *AdminAuthMiddleware) Enabled() bool {
+	return a.token != ""
+}
+
+// Authenticate rejects any request whose X-Admin-Token header doesn't
+// match the configured token, using a constant-time comparison so response
+// timing can't be used to guess the token byte-by-byte.
+func (a *AdminAuthMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.Enabled() {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			c.Abort()
+			return
+		}
+
+		supplied := c.GetHeader(AdminTokenHeader)
+		if supplied == "" || subtle.ConstantTimeCompare([]byte(supplied), []byte(a.token)) != 1 {
+			a.logger.Warn("Rejected admin request with invalid token",
+				slog.String("ip", c.ClientIP()),
+				slog.String("path", c.Request.URL.Path),
+			)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid admin token",
+				"code":  "INVALID_ADMIN_TOKEN",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
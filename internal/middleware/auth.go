@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 
+	"rate-limiting-gateway/internal/limiter"
 	"rate-limiting-gateway/internal/storage"
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
 const (
@@ -13,30 +15,58 @@ const (
 	APIKeyContextKey = "api_key"
 )
 
+// ClientInfoContextKey is the Gin context key AuthMiddleware stores the
+// resolved storage.ClientInfo under, when a KeyStore is configured.
+const ClientInfoContextKey = "client_info"
+
+// ResolvedPolicyContextKey is the Gin context key AuthMiddleware stores the
+// caller's resolved limiter.ResolvedPolicy under, when a rate limiter is
+// configured. This is informational only — RateLimitMiddleware still relies
+// on RateLimiterInterface.CheckRateLimit as the sole enforcement authority;
+// handlers can read it for logging or to surface scope/bypass info in
+// responses.
+const ResolvedPolicyContextKey = "resolved_policy"
+
 // AuthMiddleware validates API keys
 type AuthMiddleware struct {
-	redis  *storage.RedisClient
-	logger *zap.Logger
+	redis       *storage.RedisClient
+	keys        storage.KeyStore
+	rateLimiter limiter.RateLimiterInterface
+	logger      *slog.Logger
 }
 
 // NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(redis *storage.RedisClient, logger *zap.Logger) *AuthMiddleware {
+func NewAuthMiddleware(redis *storage.RedisClient, logger *slog.Logger) *AuthMiddleware {
 	return &AuthMiddleware{
 		redis:  redis,
 		logger: logger,
 	}
 }
 
+// SetKeyStore installs a pluggable API-key backend. Once set, Authenticate
+// resolves the caller's tiered ClientInfo from it (rejecting unknown or
+// disabled keys) instead of falling back to RedisClient.ValidateAPIKey's
+// any-non-empty-string check.
+func (a *AuthMiddleware) SetKeyStore(keys storage.KeyStore) {
+	a.keys = keys
+}
+
+// SetRateLimiter installs the rate limiter so Authenticate can attach the
+// caller's resolved policy to the Gin context under ResolvedPolicyContextKey.
+func (a *AuthMiddleware) SetRateLimiter(rateLimiter limiter.RateLimiterInterface) {
+	a.rateLimiter = rateLimiter
+}
+
 // Authenticate validates the API key from the request header
 func (a *AuthMiddleware) Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := c.GetHeader(APIKeyHeader)
-		
+
 		// Check if API key is provided
 		if apiKey == "" {
 			a.logger.Warn("Missing API key",
-				zap.String("ip", c.ClientIP()),
-				zap.String("user_agent", c.GetHeader("User-Agent")),
+				slog.String("ip", c.ClientIP()),
+				slog.String("user_agent", c.GetHeader("User-Agent")),
 			)
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "API key is required",
@@ -46,46 +76,120 @@ func (a *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		// Validate API key
-		valid, err := a.redis.ValidateAPIKey(c.Request.Context(), apiKey)
-		if err != nil {
-			a.logger.Error("Failed to validate API key",
-				zap.String("api_key", apiKey),
-				zap.String("ip", c.ClientIP()),
-				zap.Error(err),
+		if a.keys != nil {
+			if !a.authenticateWithKeyStore(c, apiKey) {
+				return
+			}
+		} else {
+			if !a.authenticateWithRedis(c, apiKey) {
+				return
+			}
+		}
+
+		// Store API key in context for later use
+		c.Set(APIKeyContextKey, apiKey)
+
+		if a.rateLimiter != nil {
+			if resolved, found := a.rateLimiter.ResolvePolicy(c.Request.Context(), apiKey, c.Request.URL.Path); found {
+				c.Set(ResolvedPolicyContextKey, resolved)
+			}
+		}
+
+		if a.logger.Enabled(c.Request.Context(), slog.LevelDebug) {
+			a.logger.Debug("API key validated successfully",
+				slog.String("api_key", maskAPIKey(apiKey)),
+				slog.String("ip", c.ClientIP()),
 			)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal server error",
-				"code":  "VALIDATION_ERROR",
-			})
-			c.Abort()
-			return
 		}
 
-		if !valid {
+		c.Next()
+	}
+}
+
+// authenticateWithRedis is the pre-KeyStore validation path: any non-empty
+// key recognized by RedisClient.ValidateAPIKey is accepted. It returns
+// false (having already written the response) when the request should stop.
+func (a *AuthMiddleware) authenticateWithRedis(c *gin.Context, apiKey string) bool {
+	valid, err := a.redis.ValidateAPIKey(c.Request.Context(), apiKey)
+	if err != nil {
+		a.logger.Error("Failed to validate API key",
+			slog.String("api_key", apiKey),
+			slog.String("ip", c.ClientIP()),
+			slog.Any("error", err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Internal server error",
+			"code":  "VALIDATION_ERROR",
+		})
+		c.Abort()
+		return false
+	}
+
+	if !valid {
+		a.logger.Warn("Invalid API key",
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.String("ip", c.ClientIP()),
+			slog.String("user_agent", c.GetHeader("User-Agent")),
+		)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid API key",
+			"code":  "INVALID_API_KEY",
+		})
+		c.Abort()
+		return false
+	}
+
+	return true
+}
+
+// authenticateWithKeyStore resolves apiKey's ClientInfo from the configured
+// KeyStore, rejecting unknown or disabled clients and storing the resolved
+// record in the Gin context for downstream handlers and the rate limiter.
+func (a *AuthMiddleware) authenticateWithKeyStore(c *gin.Context, apiKey string) bool {
+	info, err := a.keys.Lookup(c.Request.Context(), apiKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrClientNotFound) {
 			a.logger.Warn("Invalid API key",
-				zap.String("api_key", maskAPIKey(apiKey)),
-				zap.String("ip", c.ClientIP()),
-				zap.String("user_agent", c.GetHeader("User-Agent")),
+				slog.String("api_key", maskAPIKey(apiKey)),
+				slog.String("ip", c.ClientIP()),
+				slog.String("user_agent", c.GetHeader("User-Agent")),
 			)
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Invalid API key",
 				"code":  "INVALID_API_KEY",
 			})
 			c.Abort()
-			return
+			return false
 		}
 
-		// Store API key in context for later use
-		c.Set(APIKeyContextKey, apiKey)
-		
-		a.logger.Debug("API key validated successfully",
-			zap.String("api_key", maskAPIKey(apiKey)),
-			zap.String("ip", c.ClientIP()),
+		a.logger.Error("Failed to look up client",
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.String("ip", c.ClientIP()),
+			slog.Any("error", err),
 		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Internal server error",
+			"code":  "VALIDATION_ERROR",
+		})
+		c.Abort()
+		return false
+	}
 
-		c.Next()
+	if info.Disabled {
+		a.logger.Warn("Disabled API key used",
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.String("ip", c.ClientIP()),
+		)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "API key is disabled",
+			"code":  "CLIENT_DISABLED",
+		})
+		c.Abort()
+		return false
 	}
+
+	c.Set(ClientInfoContextKey, info)
+	return true
 }
 
 // GetAPIKeyFromContext extracts the API key from the Gin context
@@ -96,6 +200,25 @@ func GetAPIKeyFromContext(c *gin.Context) string {
 	return ""
 }
 
+// GetClientInfoFromContext extracts the resolved storage.ClientInfo from
+// the Gin context, if a KeyStore is configured and the lookup succeeded.
+func GetClientInfoFromContext(c *gin.Context) *storage.ClientInfo {
+	if info, exists := c.Get(ClientInfoContextKey); exists {
+		return info.(*storage.ClientInfo)
+	}
+	return nil
+}
+
+// GetResolvedPolicyFromContext extracts the resolved limiter.ResolvedPolicy
+// from the Gin context, if a rate limiter is configured and apiKey had an
+// assigned policy.
+func GetResolvedPolicyFromContext(c *gin.Context) (limiter.ResolvedPolicy, bool) {
+	if resolved, exists := c.Get(ResolvedPolicyContextKey); exists {
+		return resolved.(limiter.ResolvedPolicy), true
+	}
+	return limiter.ResolvedPolicy{}, false
+}
+
 // maskAPIKey masks the API key for logging (shows only first 4 and last 4 characters)
 func maskAPIKey(apiKey string) string {
 	if len(apiKey) <= 8 {
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiting-gateway/internal/config"
+	"rate-limiting-gateway/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/semaphore"
+)
+
+// ConcurrencyMiddleware bounds the number of simultaneously in-flight
+// requests, globally and per API key, independent of the request-rate
+// windows RateLimitMiddleware enforces. Requests over a cap either wait up
+// to MaxWait for a slot or fail fast, mirroring the admission-control
+// pattern Kubernetes' generic API server uses for its own max-in-flight
+// filter. Long-running routes (matched by LongRunningPatterns) are admitted
+// into a separate pool so a handful of streaming/upload requests can't
+// exhaust the slots short requests need.
+type ConcurrencyMiddleware struct {
+	config              config.ConcurrencyConfig
+	longRunningPatterns []*regexp.Regexp
+
+	global      *semaphore.Weighted
+	longRunning *semaphore.Weighted
+
+	// perKey caches one semaphore per API key, bounded to
+	// config.PerKeyCacheCapacity and evicted LRU-style, in the same style as
+	// storage.CachedKeyStore and FrontendMiddleware's frontendBucketEntry
+	// cache, so a flood of distinct API keys can't grow this map unbounded.
+	perKeyMu  sync.Mutex
+	perKey    map[string]*list.Element
+	perKeyLRU *list.List
+
+	globalInFlight      int64
+	longRunningInFlight int64
+
+	metrics *metrics.Metrics
+	logger  *slog.Logger
+}
+
+// perKeyEntry is one cached per-API-key semaphore in the perKey LRU.
+type perKeyEntry struct {
+	key string
+	sem *semaphore.Weighted
+}
+
+// NewConcurrencyMiddleware creates a new concurrency-limiting middleware. A
+// zero GlobalMaxInFlight/PerKeyMaxInFlight/LongRunningMaxInFlight disables
+// that particular cap.
+func NewConcurrencyMiddleware(cfg config.ConcurrencyConfig, metricsInstance *metrics.Metrics, logger *slog.Logger) *ConcurrencyMiddleware {
+	if cfg.PerKeyCacheCapacity <= 0 {
+		cfg.PerKeyCacheCapacity = 65536
+	}
+
+	m := &ConcurrencyMiddleware{
+		config:    cfg,
+		perKey:    make(map[string]*list.Element),
+		perKeyLRU: list.New(),
+		metrics:   metricsInstance,
+		logger:    logger,
+	}
+
+	if cfg.GlobalMaxInFlight > 0 {
+		m.global = semaphore.NewWeighted(int64(cfg.GlobalMaxInFlight))
+	}
+	if cfg.LongRunningMaxInFlight > 0 {
+		m.longRunning = semaphore.NewWeighted(int64(cfg.LongRunningMaxInFlight))
+	}
+
+	for _, pattern := range cfg.LongRunningPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			logger.Warn("Skipping invalid long-running path pattern",
+				slog.String("pattern", pattern),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		m.longRunningPatterns = append(m.longRunningPatterns, re)
+	}
+
+	return m
+}
+
+// isLongRunning reports whether path matches one of the configured
+// long-running patterns.
+func (m *ConcurrencyMiddleware) isLongRunning(path string) bool {
+	for _, re := range m.longRunningPatterns {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// perKeySemaphore returns (creating if necessary) the semaphore tracking
+// apiKey's own in-flight requests, evicting the least recently used entry
+// once the cache is full. Evicting a key just resets its in-flight count to
+// zero on next use — acceptable imprecision in exchange for bounded memory,
+// the same trade-off storage.CachedKeyStore and FrontendMiddleware make.
+func (m *ConcurrencyMiddleware) perKeySemaphore(apiKey string) *semaphore.Weighted {
+	m.perKeyMu.Lock()
+	defer m.perKeyMu.Unlock()
+
+	if el, ok := m.perKey[apiKey]; ok {
+		m.perKeyLRU.MoveToFront(el)
+		return el.Value.(*perKeyEntry).sem
+	}
+
+	entry := &perKeyEntry{
+		key: apiKey,
+		sem: semaphore.NewWeighted(int64(m.config.PerKeyMaxInFlight)),
+	}
+	el := m.perKeyLRU.PushFront(entry)
+	m.perKey[apiKey] = el
+
+	capacity := m.config.PerKeyCacheCapacity
+	if capacity > 0 {
+		for m.perKeyLRU.Len() > capacity {
+			oldest := m.perKeyLRU.Back()
+			if oldest == nil {
+				break
+			}
+			m.perKeyLRU.Remove(oldest)
+			delete(m.perKey, oldest.Value.(*perKeyEntry).key)
+		}
+	}
+
+	return entry.sem
+}
+
+// acquire tries to take one slot from sem, waiting up to m.config.MaxWait.
+// A non-positive MaxWait fails fast instead of waiting at all.
+func (m *ConcurrencyMiddleware) acquire(ctx context.Context, sem *semaphore.Weighted) bool {
+	if m.config.MaxWait <= 0 {
+		return sem.TryAcquire(1)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, m.config.MaxWait)
+	defer cancel()
+	return sem.Acquire(waitCtx, 1) == nil
+}
+
+// Limit admits the request into the global (or long-running) pool and, if
+// PerKeyMaxInFlight is set, the caller's own per-key pool, rejecting it with
+// 503 and Retry-After when no slot is available in time.
+func (m *ConcurrencyMiddleware) Limit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		longRunning := m.isLongRunning(c.Request.URL.Path)
+
+		pool := m.global
+		poolName := "global"
+		inFlight := &m.globalInFlight
+		if longRunning && m.longRunning != nil {
+			pool = m.longRunning
+			poolName = "long_running"
+			inFlight = &m.longRunningInFlight
+		}
+
+		if pool != nil {
+			if !m.acquire(c.Request.Context(), pool) {
+				m.reject(c, poolName)
+				return
+			}
+			defer pool.Release(1)
+			defer m.setInFlight(inFlight, poolName, -1)
+			m.setInFlight(inFlight, poolName, 1)
+		}
+
+		if m.config.PerKeyMaxInFlight > 0 {
+			apiKey := GetAPIKeyFromContext(c)
+			if apiKey != "" {
+				keySem := m.perKeySemaphore(apiKey)
+				if !m.acquire(c.Request.Context(), keySem) {
+					m.reject(c, poolName)
+					return
+				}
+				defer keySem.Release(1)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// setInFlight adjusts the in-flight counter for pool by delta and publishes
+// it to the gauge, if metrics are configured.
+func (m *ConcurrencyMiddleware) setInFlight(counter *int64, pool string, delta int64) {
+	current := atomic.AddInt64(counter, delta)
+	if m.metrics != nil {
+		m.metrics.SetInFlight(pool, current)
+	}
+}
+
+// reject responds with 503 and a Retry-After hint when no concurrency slot
+// became available within MaxWait.
+func (m *ConcurrencyMiddleware) reject(c *gin.Context, pool string) {
+	retryAfter := 1
+	if m.config.MaxWait > 0 {
+		retryAfter = int(m.config.MaxWait.Seconds())
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+	}
+
+	m.logger.Warn("Concurrency limit exceeded",
+		slog.String("pool", pool),
+		slog.String("ip", c.ClientIP()),
+	)
+
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error": "Too many concurrent requests",
+		"code":  "CONCURRENCY_LIMIT_EXCEEDED",
+	})
+	c.Abort()
+}
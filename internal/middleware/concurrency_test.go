@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rate-limiting-gateway/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyMiddleware_PerKeyCacheIsBounded(t *testing.T) {
+	cfg := config.ConcurrencyConfig{
+		PerKeyMaxInFlight:   1,
+		PerKeyCacheCapacity: 2,
+	}
+	m := NewConcurrencyMiddleware(cfg, nil, slog.Default())
+
+	m.perKeySemaphore("key-a")
+	m.perKeySemaphore("key-b")
+	m.perKeySemaphore("key-c")
+
+	assert.LessOrEqual(t, len(m.perKey), 2, "perKey cache should never exceed PerKeyCacheCapacity")
+	assert.Equal(t, 2, m.perKeyLRU.Len())
+
+	// key-a was the least recently used and should have been evicted first.
+	_, stillCached := m.perKey["key-a"]
+	assert.False(t, stillCached)
+}
+
+func TestConcurrencyMiddleware_PerKeyCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cfg := config.ConcurrencyConfig{
+		PerKeyMaxInFlight:   1,
+		PerKeyCacheCapacity: 2,
+	}
+	m := NewConcurrencyMiddleware(cfg, nil, slog.Default())
+
+	m.perKeySemaphore("key-a")
+	m.perKeySemaphore("key-b")
+	m.perKeySemaphore("key-a") // touch key-a again so key-b becomes the LRU entry
+	m.perKeySemaphore("key-c")
+
+	_, aCached := m.perKey["key-a"]
+	_, bCached := m.perKey["key-b"]
+	assert.True(t, aCached, "recently touched key-a should still be cached")
+	assert.False(t, bCached, "untouched key-b should have been evicted")
+}
+
+func TestConcurrencyMiddleware_Limit_RejectsOverPerKeyCap(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.ConcurrencyConfig{PerKeyMaxInFlight: 1}
+	m := NewConcurrencyMiddleware(cfg, nil, slog.Default())
+
+	router := gin.New()
+	blockCh := make(chan struct{})
+	holdingSlot := make(chan struct{})
+	router.Use(func(c *gin.Context) {
+		c.Set(APIKeyContextKey, "same-key")
+		c.Next()
+	})
+	router.Use(m.Limit())
+	router.GET("/proxy", func(c *gin.Context) {
+		close(holdingSlot)
+		<-blockCh
+		c.Status(http.StatusOK)
+	})
+
+	// First request holds the only per-key slot until released.
+	firstDone := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/proxy", nil)
+		router.ServeHTTP(w, req)
+		firstDone <- w
+	}()
+
+	<-holdingSlot // wait until the first request is actually holding the slot
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/proxy", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	close(blockCh)
+	<-firstDone
+}
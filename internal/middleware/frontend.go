@@ -0,0 +1,306 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"rate-limiting-gateway/internal/config"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// peekBody reads the request body and restores it so downstream handlers
+// (auth, proxy) can still read it.
+func peekBody(c *gin.Context) ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+	return body, nil
+}
+
+// jsonRPCRequest is the minimal shape needed to pull the method name out of a
+// JSON-RPC request body without fully decoding params.
+type jsonRPCRequest struct {
+	Method string `json:"method"`
+}
+
+// frontendBucketEntry is one token-bucket slot in FrontendMiddleware's
+// bounded cache.
+type frontendBucketEntry struct {
+	key      string
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// FrontendMiddleware rate-limits unauthenticated/pre-auth traffic by client
+// IP (and, for JSON-RPC bodies, by method name), orthogonal to the API-key
+// limits enforced further down the chain. Each bucket is a token-bucket
+// (golang.org/x/time/rate) held in an LRU cache bounded to
+// FrontendBucketCapacity entries, so memory can't grow unbounded under an
+// IP-spoofing flood. Entries are evicted only when the cache is full; an
+// idle bucket is not expired by age. It never touches Redis, which is the whole point: this
+// is the layer that keeps invalid-key floods and metrics-scrape abuse from
+// reaching Redis-backed API-key validation at all.
+type FrontendMiddleware struct {
+	config config.RateLimitConfig
+	logger *slog.Logger
+
+	exemptCIDRs       []*net.IPNet
+	trustedProxyCIDRs []*net.IPNet
+	exemptUAPatterns  []*regexp.Regexp
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// NewFrontendMiddleware creates a new frontend rate limiting middleware.
+func NewFrontendMiddleware(cfg config.RateLimitConfig, logger *slog.Logger) *FrontendMiddleware {
+	f := &FrontendMiddleware{
+		config:  cfg,
+		logger:  logger,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+
+	f.exemptCIDRs = parseCIDRs(cfg.ExemptCIDRs, logger, "exempt")
+	f.trustedProxyCIDRs = parseCIDRs(cfg.TrustedProxyCIDRs, logger, "trusted proxy")
+
+	for _, pattern := range cfg.ExemptUserAgentPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			logger.Warn("Skipping invalid exempt user-agent pattern",
+				slog.String("pattern", pattern),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		f.exemptUAPatterns = append(f.exemptUAPatterns, re)
+	}
+
+	return f
+}
+
+// parseCIDRs parses each entry in raw as a CIDR range, logging and skipping
+// (rather than failing) any that don't parse.
+func parseCIDRs(raw []string, logger *slog.Logger, kind string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, entry := range raw {
+		_, cidr, err := net.ParseCIDR(entry)
+		if err != nil {
+			logger.Warn("Skipping invalid "+kind+" CIDR",
+				slog.String("cidr", entry),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		nets = append(nets, cidr)
+	}
+	return nets
+}
+
+// RateLimit enforces the per-IP (and per-JSON-RPC-method) frontend limit,
+// skipping it entirely for exempt user agents, origins, or client IPs.
+func (f *FrontendMiddleware) RateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := f.clientIP(c)
+
+		if f.isExempt(c, ip) {
+			c.Next()
+			return
+		}
+
+		limit := f.config.FrontendRequestsPerSecond
+		bucketKey := frontendScope(c.Request.URL.Path) + ":" + ip
+
+		if method := jsonRPCMethod(c); method != "" {
+			bucketKey = bucketKey + ":" + method
+			if override, ok := f.config.PerMethodLimits[method]; ok {
+				limit = override
+			}
+		}
+
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if !f.allow(bucketKey, limit) {
+			f.logger.Warn("Frontend rate limit exceeded",
+				slog.String("ip", ip),
+				slog.String("bucket", bucketKey),
+				slog.Int("limit", limit),
+			)
+			c.Data(http.StatusTooManyRequests, "application/json", jsonRPCRateLimitedBody())
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// allow consumes one token from bucketKey's limiter, creating it (sized to
+// limit requests/sec, with a burst equal to limit) on first use.
+func (f *FrontendMiddleware) allow(bucketKey string, limit int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+
+	if el, ok := f.entries[bucketKey]; ok {
+		entry := el.Value.(*frontendBucketEntry)
+		entry.lastSeen = now
+		f.order.MoveToFront(el)
+		return entry.limiter.Allow()
+	}
+
+	entry := &frontendBucketEntry{
+		key:      bucketKey,
+		limiter:  rate.NewLimiter(rate.Limit(limit), limit),
+		lastSeen: now,
+	}
+	el := f.order.PushFront(entry)
+	f.entries[bucketKey] = el
+
+	capacity := f.config.FrontendBucketCapacity
+	if capacity > 0 {
+		for f.order.Len() > capacity {
+			oldest := f.order.Back()
+			if oldest == nil {
+				break
+			}
+			f.order.Remove(oldest)
+			delete(f.entries, oldest.Value.(*frontendBucketEntry).key)
+		}
+	}
+
+	return entry.limiter.Allow()
+}
+
+// frontendScope buckets a path into its own rate limit pool so, e.g., a
+// metrics-scrape flood can't exhaust the budget /api/* traffic depends on.
+func frontendScope(path string) string {
+	switch {
+	case path == "/health":
+		return "health"
+	case path == "/metrics":
+		return "metrics"
+	case strings.HasPrefix(path, "/api"):
+		return "api"
+	default:
+		return "other"
+	}
+}
+
+// isExempt reports whether the request's User-Agent, Origin, or client IP
+// matches a configured exemption.
+func (f *FrontendMiddleware) isExempt(c *gin.Context, ip string) bool {
+	userAgent := c.GetHeader("User-Agent")
+	for _, exempt := range f.config.ExemptUserAgents {
+		if exempt == userAgent {
+			return true
+		}
+	}
+	for _, re := range f.exemptUAPatterns {
+		if re.MatchString(userAgent) {
+			return true
+		}
+	}
+
+	origin := c.GetHeader("Origin")
+	for _, exempt := range f.config.ExemptOrigins {
+		if exempt == origin {
+			return true
+		}
+	}
+
+	if parsed := net.ParseIP(ip); parsed != nil {
+		for _, cidr := range f.exemptCIDRs {
+			if cidr.Contains(parsed) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// jsonRPCMethod extracts the "method" field from a JSON body without
+// consuming the request body for downstream handlers.
+func jsonRPCMethod(c *gin.Context) string {
+	if !strings.HasPrefix(c.GetHeader("Content-Type"), "application/json") {
+		return ""
+	}
+
+	body, err := peekBody(c)
+	if err != nil || len(body) == 0 {
+		return ""
+	}
+
+	var rpcReq jsonRPCRequest
+	if err := json.Unmarshal(body, &rpcReq); err != nil {
+		return ""
+	}
+
+	return rpcReq.Method
+}
+
+// jsonRPCRateLimitedBody builds the JSON-RPC-style error body returned on 429,
+// matching the shape upstream RPC clients already know how to parse.
+func jsonRPCRateLimitedBody() []byte {
+	body, _ := json.Marshal(gin.H{
+		"code":    -32016,
+		"message": "rate limited",
+	})
+	return body
+}
+
+// clientIP resolves the request's client IP. X-Forwarded-For/X-Real-IP are
+// only trusted when the immediate peer (Gin's own RemoteIP) falls within a
+// configured TrustedProxyCIDRs range; otherwise a client could spoof its way
+// into another caller's bucket by setting the header itself.
+func (f *FrontendMiddleware) clientIP(c *gin.Context) string {
+	remoteIP := net.ParseIP(c.RemoteIP())
+	trusted := remoteIP != nil && len(f.trustedProxyCIDRs) > 0
+	if trusted {
+		trusted = false
+		for _, cidr := range f.trustedProxyCIDRs {
+			if cidr.Contains(remoteIP) {
+				trusted = true
+				break
+			}
+		}
+	}
+
+	if !trusted {
+		return c.RemoteIP()
+	}
+
+	if forwarded := c.GetHeader("X-Forwarded-For"); forwarded != "" {
+		if idx := strings.Index(forwarded, ","); idx != -1 {
+			return strings.TrimSpace(forwarded[:idx])
+		}
+		return strings.TrimSpace(forwarded)
+	}
+
+	if realIP := c.GetHeader("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+
+	return c.ClientIP()
+}
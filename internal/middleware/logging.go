@@ -3,19 +3,19 @@ package middleware
 import (
 	"bytes"
 	"io"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
 // LoggingMiddleware handles request logging
 type LoggingMiddleware struct {
-	logger *zap.Logger
+	logger *slog.Logger
 }
 
 // NewLoggingMiddleware creates a new logging middleware
-func NewLoggingMiddleware(logger *zap.Logger) *LoggingMiddleware {
+func NewLoggingMiddleware(logger *slog.Logger) *LoggingMiddleware {
 	return &LoggingMiddleware{
 		logger: logger,
 	}
@@ -50,27 +50,27 @@ func (l *LoggingMiddleware) LogRequest() gin.HandlerFunc {
 
 		// Log the request
 		l.logger.Info("HTTP Request",
-			zap.String("method", c.Request.Method),
-			zap.String("path", c.Request.URL.Path),
-			zap.String("query", c.Request.URL.RawQuery),
-			zap.String("ip", c.ClientIP()),
-			zap.String("user_agent", c.GetHeader("User-Agent")),
-			zap.String("api_key", maskAPIKey(apiKey)),
-			zap.Int("status", c.Writer.Status()),
-			zap.Duration("duration", duration),
-			zap.Int("response_size", c.Writer.Size()),
-			zap.String("referer", c.GetHeader("Referer")),
-			zap.String("forwarded_for", c.GetHeader("X-Forwarded-For")),
-			zap.String("real_ip", c.GetHeader("X-Real-IP")),
-			zap.Any("rate_limit_info", rateLimitInfo),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("query", c.Request.URL.RawQuery),
+			slog.String("ip", c.ClientIP()),
+			slog.String("user_agent", c.GetHeader("User-Agent")),
+			slog.String("api_key", maskAPIKey(apiKey)),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("duration", duration),
+			slog.Int("response_size", c.Writer.Size()),
+			slog.String("referer", c.GetHeader("Referer")),
+			slog.String("forwarded_for", c.GetHeader("X-Forwarded-For")),
+			slog.String("real_ip", c.GetHeader("X-Real-IP")),
+			slog.Any("rate_limit_info", rateLimitInfo),
 		)
 
 		// Log request body for debugging (only for non-GET requests and small bodies)
 		if len(bodyBytes) > 0 && len(bodyBytes) < 1024 && c.Request.Method != "GET" {
 			l.logger.Debug("Request body",
-				zap.String("method", c.Request.Method),
-				zap.String("path", c.Request.URL.Path),
-				zap.String("body", string(bodyBytes)),
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.String("body", string(bodyBytes)),
 			)
 		}
 	}
@@ -87,13 +87,13 @@ func (l *LoggingMiddleware) LogError() gin.HandlerFunc {
 			
 			for _, err := range c.Errors {
 				l.logger.Error("Request error",
-					zap.String("method", c.Request.Method),
-					zap.String("path", c.Request.URL.Path),
-					zap.String("ip", c.ClientIP()),
-					zap.String("api_key", maskAPIKey(apiKey)),
-					zap.Int("status", c.Writer.Status()),
-									zap.Error(err.Err),
-				zap.String("error_type", string(err.Type)),
+					slog.String("method", c.Request.Method),
+					slog.String("path", c.Request.URL.Path),
+					slog.String("ip", c.ClientIP()),
+					slog.String("api_key", maskAPIKey(apiKey)),
+					slog.Int("status", c.Writer.Status()),
+									slog.Any("error", err.Err),
+				slog.String("error_type", string(err.Type)),
 				)
 			}
 		}
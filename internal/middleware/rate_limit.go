@@ -1,23 +1,23 @@
 package middleware
 
 import (
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"rate-limiting-gateway/internal/limiter"
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
 // RateLimitMiddleware handles rate limiting
 type RateLimitMiddleware struct {
 	rateLimiter limiter.RateLimiterInterface
-	logger      *zap.Logger
+	logger      *slog.Logger
 }
 
 // NewRateLimitMiddleware creates a new rate limiting middleware
-func NewRateLimitMiddleware(rateLimiter limiter.RateLimiterInterface, logger *zap.Logger) *RateLimitMiddleware {
+func NewRateLimitMiddleware(rateLimiter limiter.RateLimiterInterface, logger *slog.Logger) *RateLimitMiddleware {
 	return &RateLimitMiddleware{
 		rateLimiter: rateLimiter,
 		logger:      logger,
@@ -38,12 +38,12 @@ func (r *RateLimitMiddleware) RateLimit() gin.HandlerFunc {
 		}
 
 		// Check rate limits
-		result, err := r.rateLimiter.CheckRateLimit(c.Request.Context(), apiKey)
+		result, err := r.rateLimiter.CheckRateLimit(c.Request.Context(), apiKey, c.Request.URL.Path, c.ClientIP())
 		if err != nil {
 			r.logger.Error("Failed to check rate limit",
-				zap.String("api_key", maskAPIKey(apiKey)),
-				zap.String("ip", c.ClientIP()),
-				zap.Error(err),
+				slog.String("api_key", maskAPIKey(apiKey)),
+				slog.String("ip", c.ClientIP()),
+				slog.Any("error", err),
 			)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal server error",
@@ -71,6 +71,10 @@ func (r *RateLimitMiddleware) RateLimit() gin.HandlerFunc {
 				statusCode = http.StatusTooManyRequests
 				errorCode = "MONTHLY_QUOTA_EXCEEDED"
 				errorMessage = "Monthly quota exceeded"
+			} else if result.Window == "denied" {
+				statusCode = http.StatusForbidden
+				errorCode = "POLICY_DENIED"
+				errorMessage = "Request denied by policy"
 			} else {
 				statusCode = http.StatusTooManyRequests
 				errorCode = "RATE_LIMIT_EXCEEDED"
@@ -78,13 +82,13 @@ func (r *RateLimitMiddleware) RateLimit() gin.HandlerFunc {
 			}
 
 			r.logger.Warn("Rate limit exceeded",
-				zap.String("api_key", maskAPIKey(apiKey)),
-				zap.String("ip", c.ClientIP()),
-				zap.String("window", result.Window),
-				zap.Int("limit", result.Limit),
-				zap.Int("quota_used", result.QuotaUsed),
-				zap.Int("quota_limit", result.QuotaLimit),
-				zap.Time("reset_time", result.ResetTime),
+				slog.String("api_key", maskAPIKey(apiKey)),
+				slog.String("ip", c.ClientIP()),
+				slog.String("window", result.Window),
+				slog.Int("limit", result.Limit),
+				slog.Int("quota_used", result.QuotaUsed),
+				slog.Int("quota_limit", result.QuotaLimit),
+				slog.Time("reset_time", result.ResetTime),
 			)
 
 			c.JSON(statusCode, gin.H{
@@ -110,28 +114,13 @@ func (r *RateLimitMiddleware) RateLimit() gin.HandlerFunc {
 		c.Header("X-RateLimit-Window", result.Window)
 		c.Header("X-RateLimit-QuotaUsed", strconv.Itoa(result.QuotaUsed))
 		c.Header("X-RateLimit-QuotaLimit", strconv.Itoa(result.QuotaLimit))
+		if result.BypassReason != "" {
+			c.Header("X-RateLimit-Bypass-Reason", result.BypassReason)
+		}
+		if resolved, ok := GetResolvedPolicyFromContext(c); ok && resolved.Source != "" {
+			c.Header("X-RateLimit-Policy-Source", resolved.Source)
+		}
 
 		c.Next()
 	}
-}
-
-// IncrementRateLimit increments the rate limit counters after a successful request
-func (r *RateLimitMiddleware) IncrementRateLimit() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Only increment if the request was successful
-		if c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
-			apiKey := GetAPIKeyFromContext(c)
-			if apiKey != "" {
-				if err := r.rateLimiter.IncrementRateLimit(c.Request.Context(), apiKey); err != nil {
-					r.logger.Error("Failed to increment rate limit",
-						zap.String("api_key", maskAPIKey(apiKey)),
-						zap.String("ip", c.ClientIP()),
-						zap.Error(err),
-					)
-					// Don't fail the request if rate limit increment fails
-					// Just log the error
-				}
-			}
-		}
-	}
 } 
\ No newline at end of file
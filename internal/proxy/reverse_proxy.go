@@ -0,0 +1,201 @@
+// Package proxy implements the gateway's streaming reverse proxy: hop-by-hop
+// header stripping, X-Forwarded-* rewriting, and websocket upgrade support
+// that net/http's client can't give us on its own.
+package proxy
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"log/slog"
+)
+
+// hopHeaders are connection-specific and must not be forwarded end-to-end,
+// per RFC 7230 section 6.1.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Config controls how the reverse proxy talks to the upstream target.
+type Config struct {
+	// TargetURL is the upstream base URL requests are forwarded to.
+	TargetURL string
+	// FlushInterval is passed straight through to httputil.ReverseProxy;
+	// see its docs for the zero-value and negative-value behavior.
+	FlushInterval time.Duration
+}
+
+// NewReverseProxy builds an httputil.ReverseProxy for config.TargetURL that
+// streams request/response bodies, strips hop-by-hop headers, and rewrites
+// X-Forwarded-For/-Host/-Proto. It does not handle websocket upgrades; see
+// IsWebsocketUpgrade and ServeWebsocket for those.
+func NewReverseProxy(config Config, logger *slog.Logger) (*httputil.ReverseProxy, error) {
+	target, err := url.Parse(config.TargetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := httputil.NewSingleHostReverseProxy(target)
+	rp.FlushInterval = config.FlushInterval
+
+	director := rp.Director
+	rp.Director = func(req *http.Request) {
+		director(req)
+		StripHopHeaders(req.Header)
+		SetForwardedHeaders(req)
+	}
+
+	rp.ModifyResponse = func(resp *http.Response) error {
+		StripHopHeaders(resp.Header)
+		return nil
+	}
+
+	rp.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		logger.Error("Reverse proxy error",
+			slog.String("target_url", config.TargetURL),
+			slog.Any("error", err),
+		)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	return rp, nil
+}
+
+// StripHopHeaders deletes every header in hopHeaders from h in place.
+func StripHopHeaders(h http.Header) {
+	for _, header := range hopHeaders {
+		h.Del(header)
+	}
+}
+
+// SetForwardedHeaders appends req's client IP to X-Forwarded-For (preserving
+// whatever chain, and first original client IP, already present) and sets
+// X-Forwarded-Host/-Proto, so the upstream can see the true origin of a
+// request that's passed through one or more proxies.
+func SetForwardedHeaders(req *http.Request) {
+	if clientIP, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		if prior := req.Header.Get("X-Forwarded-For"); prior != "" {
+			req.Header.Set("X-Forwarded-For", prior+", "+clientIP)
+		} else {
+			req.Header.Set("X-Forwarded-For", clientIP)
+		}
+	}
+
+	if req.Header.Get("X-Forwarded-Host") == "" {
+		req.Header.Set("X-Forwarded-Host", req.Host)
+	}
+
+	proto := "http"
+	if req.TLS != nil {
+		proto = "https"
+	}
+	req.Header.Set("X-Forwarded-Proto", proto)
+}
+
+// IsWebsocketUpgrade reports whether req is requesting a websocket upgrade,
+// which httputil.ReverseProxy can't proxy on its own since it never exposes
+// the underlying hijacked connection.
+func IsWebsocketUpgrade(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(req.Header.Get("Connection")), "upgrade")
+}
+
+// ServeWebsocket dials targetURL directly, forwards the upgrade request, and
+// then splices the hijacked client connection and the upstream connection
+// together so frames flow bidirectionally until either side closes.
+func ServeWebsocket(w http.ResponseWriter, req *http.Request, targetURL string, logger *slog.Logger) {
+	target, err := url.Parse(targetURL)
+	if err != nil {
+		http.Error(w, "Invalid upstream target", http.StatusInternalServerError)
+		return
+	}
+
+	upstreamAddr := target.Host
+	if _, _, err := net.SplitHostPort(upstreamAddr); err != nil {
+		if target.Scheme == "https" || target.Scheme == "wss" {
+			upstreamAddr = net.JoinHostPort(upstreamAddr, "443")
+		} else {
+			upstreamAddr = net.JoinHostPort(upstreamAddr, "80")
+		}
+	}
+
+	upstreamConn, err := net.DialTimeout("tcp", upstreamAddr, 10*time.Second)
+	if err != nil {
+		logger.Error("Failed to dial websocket upstream",
+			slog.String("target_addr", upstreamAddr),
+			slog.Any("error", err),
+		)
+		http.Error(w, "Upstream service unavailable", http.StatusBadGateway)
+		return
+	}
+	defer upstreamConn.Close()
+
+	outReq := req.Clone(req.Context())
+	outReq.URL.Scheme = target.Scheme
+	outReq.URL.Host = target.Host
+	outReq.RequestURI = ""
+	SetForwardedHeaders(outReq)
+
+	if err := outReq.Write(upstreamConn); err != nil {
+		logger.Error("Failed to write websocket upgrade request upstream", slog.Any("error", err))
+		http.Error(w, "Upstream service unavailable", http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Websocket upgrade not supported", http.StatusInternalServerError)
+		return
+	}
+	clientConn, clientBuf, err := hijacker.Hijack()
+	if err != nil {
+		logger.Error("Failed to hijack client connection", slog.Any("error", err))
+		return
+	}
+	defer clientConn.Close()
+
+	upstreamReader := bufio.NewReader(upstreamConn)
+	resp, err := http.ReadResponse(upstreamReader, outReq)
+	if err != nil {
+		logger.Error("Failed to read websocket upgrade response", slog.Any("error", err))
+		return
+	}
+	if err := resp.Write(clientConn); err != nil {
+		logger.Error("Failed to write websocket upgrade response to client", slog.Any("error", err))
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go splice(done, upstreamConn, clientBuf)
+	go splice(done, clientConn, upstreamReader)
+	<-done
+}
+
+func splice(done chan<- struct{}, dst net.Conn, src interface{ Read([]byte) (int, error) }) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	done <- struct{}{}
+}
@@ -0,0 +1,82 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripHopHeaders_RemovesOnlyHopByHopHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Connection", "keep-alive")
+	h.Set("Keep-Alive", "timeout=5")
+	h.Set("Transfer-Encoding", "chunked")
+	h.Set("Content-Type", "application/json")
+
+	StripHopHeaders(h)
+
+	assert.Empty(t, h.Get("Connection"))
+	assert.Empty(t, h.Get("Keep-Alive"))
+	assert.Empty(t, h.Get("Transfer-Encoding"))
+	assert.Equal(t, "application/json", h.Get("Content-Type"), "end-to-end headers must not be stripped")
+}
+
+func TestSetForwardedHeaders_SetsFreshHeadersWhenAbsent(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://gateway.example/proxy/test", nil)
+	req.RemoteAddr = "203.0.113.9:54321"
+	req.Host = "gateway.example"
+
+	SetForwardedHeaders(req)
+
+	assert.Equal(t, "203.0.113.9", req.Header.Get("X-Forwarded-For"))
+	assert.Equal(t, "gateway.example", req.Header.Get("X-Forwarded-Host"))
+	assert.Equal(t, "http", req.Header.Get("X-Forwarded-Proto"))
+}
+
+func TestSetForwardedHeaders_AppendsToExistingForwardedForChain(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://gateway.example/proxy/test", nil)
+	req.RemoteAddr = "203.0.113.9:54321"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	SetForwardedHeaders(req)
+
+	assert.Equal(t, "198.51.100.1, 203.0.113.9", req.Header.Get("X-Forwarded-For"))
+}
+
+func TestSetForwardedHeaders_PreservesExistingForwardedHost(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://gateway.example/proxy/test", nil)
+	req.RemoteAddr = "203.0.113.9:54321"
+	req.Header.Set("X-Forwarded-Host", "original.example")
+
+	SetForwardedHeaders(req)
+
+	assert.Equal(t, "original.example", req.Header.Get("X-Forwarded-Host"))
+}
+
+func TestSetForwardedHeaders_ProtoIsHTTPSWhenTLSPresent(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://gateway.example/proxy/test", nil)
+	req.RemoteAddr = "203.0.113.9:54321"
+	req.TLS = &tls.ConnectionState{}
+
+	SetForwardedHeaders(req)
+
+	assert.Equal(t, "https", req.Header.Get("X-Forwarded-Proto"))
+}
+
+func TestIsWebsocketUpgrade(t *testing.T) {
+	upgrade := httptest.NewRequest("GET", "/ws", nil)
+	upgrade.Header.Set("Upgrade", "websocket")
+	upgrade.Header.Set("Connection", "Upgrade")
+	assert.True(t, IsWebsocketUpgrade(upgrade))
+
+	notUpgrade := httptest.NewRequest("GET", "/ws", nil)
+	assert.False(t, IsWebsocketUpgrade(notUpgrade))
+
+	wrongUpgradeHeader := httptest.NewRequest("GET", "/ws", nil)
+	wrongUpgradeHeader.Header.Set("Upgrade", "h2c")
+	wrongUpgradeHeader.Header.Set("Connection", "Upgrade")
+	assert.False(t, IsWebsocketUpgrade(wrongUpgradeHeader))
+}
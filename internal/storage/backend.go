@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// backendSemaphoreAcquireScript atomically increments backend:<name>:inflight
+// and only grants the slot if the resulting count is still within limit,
+// undoing the increment otherwise. Used to enforce BackendLimiter's stricter
+// concurrency cap on a degraded (half-open) backend across every gateway
+// instance, not just the one handling a given request.
+// ARGV: [1]=limit [2]=ttl_seconds. Returns allowed (0/1).
+var backendSemaphoreAcquireScript = redis.NewScript(`
+local current = redis.call("INCR", KEYS[1])
+redis.call("EXPIRE", KEYS[1], ARGV[2])
+if current > tonumber(ARGV[1]) then
+	redis.call("DECR", KEYS[1])
+	return 0
+end
+return 1
+`)
+
+// backendSemaphoreTTL bounds how long an inflight slot can be held before
+// expiring on its own, so a gateway instance that crashes mid-request
+// doesn't leak the slot forever.
+const backendSemaphoreTTL = 60 * time.Second
+
+// AcquireBackendSlot attempts to reserve one of limit concurrent in-flight
+// slots for backend, returning whether it was granted. Every granted
+// acquisition must be paired with a ReleaseBackendSlot call once the
+// request completes.
+func (r *RedisClient) AcquireBackendSlot(ctx context.Context, backend string, limit int) (bool, error) {
+	key := fmt.Sprintf("backend:%s:inflight", backend)
+
+	res, err := backendSemaphoreAcquireScript.Run(ctx, r.client, []string{key}, limit, int(backendSemaphoreTTL.Seconds())).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire backend slot: %w", err)
+	}
+	return res.(int64) == 1, nil
+}
+
+// ReleaseBackendSlot releases a slot previously granted by AcquireBackendSlot.
+func (r *RedisClient) ReleaseBackendSlot(ctx context.Context, backend string) error {
+	key := fmt.Sprintf("backend:%s:inflight", backend)
+	return r.client.Decr(ctx, key).Err()
+}
+
+// RecordBackendRequest increments backend:<name>:rps, expiring after window
+// so the counter reflects requests within a recent rolling period rather
+// than an all-time total.
+func (r *RedisClient) RecordBackendRequest(ctx context.Context, backend string, window time.Duration) error {
+	return r.incrWithExpiry(ctx, fmt.Sprintf("backend:%s:rps", backend), window)
+}
+
+// RecordBackendError increments backend:<name>:errors, alongside
+// RecordBackendRequest.
+func (r *RedisClient) RecordBackendError(ctx context.Context, backend string, window time.Duration) error {
+	return r.incrWithExpiry(ctx, fmt.Sprintf("backend:%s:errors", backend), window)
+}
+
+func (r *RedisClient) incrWithExpiry(ctx context.Context, key string, window time.Duration) error {
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to increment %s: %w", key, err)
+	}
+	// The TTL is set only when the counter is created, so steady traffic
+	// can't keep pushing it back and turn the count into an all-time total.
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, window).Err(); err != nil {
+			return fmt.Errorf("failed to set expiry on %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// backendLatencySamples bounds how many recent latency samples
+// GetBackendStats estimates backend:<name>:latency_p99 from.
+const backendLatencySamples = 1000
+
+// RecordBackendLatency appends latencyMs to backend:<name>:latency_p99, a
+// capped rolling sample list used to estimate the backend's p99 response
+// time.
+func (r *RedisClient) RecordBackendLatency(ctx context.Context, backend string, latencyMs int64) error {
+	key := fmt.Sprintf("backend:%s:latency_p99", backend)
+
+	pipe := r.client.Pipeline()
+	pipe.LPush(ctx, key, latencyMs)
+	pipe.LTrim(ctx, key, 0, backendLatencySamples-1)
+	pipe.Expire(ctx, key, time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record backend latency: %w", err)
+	}
+	return nil
+}
+
+// GetBackendStats reports backend's rolling request/error counts and an
+// estimated p99 latency in milliseconds, for /admin/backends.
+func (r *RedisClient) GetBackendStats(ctx context.Context, backend string) (requests, errors, latencyP99Ms int64, err error) {
+	requests, err = r.getCounter(ctx, fmt.Sprintf("backend:%s:rps", backend))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	errors, err = r.getCounter(ctx, fmt.Sprintf("backend:%s:errors", backend))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	latencyP99Ms, err = r.getLatencyP99(ctx, backend)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return requests, errors, latencyP99Ms, nil
+}
+
+func (r *RedisClient) getCounter(ctx context.Context, key string) (int64, error) {
+	val, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func (r *RedisClient) getLatencyP99(ctx context.Context, backend string) (int64, error) {
+	key := fmt.Sprintf("backend:%s:latency_p99", backend)
+
+	vals, err := r.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get backend latency samples: %w", err)
+	}
+	if len(vals) == 0 {
+		return 0, nil
+	}
+
+	samples := make([]int64, 0, len(vals))
+	for _, v := range vals {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, ms)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	idx := int(float64(len(samples)) * 0.99)
+	if idx >= len(samples) {
+		idx = len(samples) - 1
+	}
+	return samples[idx], nil
+}
@@ -0,0 +1,27 @@
+package storage
+
+import "context"
+
+// bypassKeysSetKey is the Redis SET backing the dynamic rate-limit bypass
+// list, mirroring the "valid_keys" set used for API key validation.
+const bypassKeysSetKey = "bypass_keys"
+
+// AddBypassKey adds apiKey to the dynamic set of rate-limit bypass keys.
+func (r *RedisClient) AddBypassKey(ctx context.Context, apiKey string) error {
+	return r.client.SAdd(ctx, bypassKeysSetKey, apiKey).Err()
+}
+
+// RemoveBypassKey removes apiKey from the dynamic bypass key set.
+func (r *RedisClient) RemoveBypassKey(ctx context.Context, apiKey string) error {
+	return r.client.SRem(ctx, bypassKeysSetKey, apiKey).Err()
+}
+
+// IsBypassKey reports whether apiKey is in the dynamic bypass key set.
+func (r *RedisClient) IsBypassKey(ctx context.Context, apiKey string) (bool, error) {
+	return r.client.SIsMember(ctx, bypassKeysSetKey, apiKey).Result()
+}
+
+// GetBypassKeys returns every API key currently in the dynamic bypass set.
+func (r *RedisClient) GetBypassKeys(ctx context.Context) ([]string, error) {
+	return r.client.SMembers(ctx, bypassKeysSetKey).Result()
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClientNotFound is returned by KeyStore.Lookup when apiKey has no
+// registered client record.
+var ErrClientNotFound = errors.New("client not found")
+
+// ClientInfo carries the resolved identity and tiered limits for one API
+// key, as looked up from a KeyStore. Zero-valued RequestsPer* fields mean
+// "no override" — the gateway's global RateLimitConfig applies instead.
+type ClientInfo struct {
+	APIKey            string
+	Tier              string
+	RequestsPerMinute int
+	RequestsPerHour   int
+	RequestsPerDay    int
+	MonthlyQuota      int
+	AllowedAPIs       []string
+
+	// Disabled rejects every request for this key outright, regardless of
+	// rate. Unlimited skips rate limiting entirely (quota tracking still
+	// runs, for reporting).
+	Disabled  bool
+	Unlimited bool
+}
+
+// KeyStore resolves API keys to their client record and supports the CRUD
+// operations backing the admin API. Implementations: PostgresKeyStore (for
+// larger deployments) and RedisKeyStore (for small ones that don't want a
+// separate database). CachedKeyStore wraps either with an LRU+TTL cache.
+type KeyStore interface {
+	// Lookup returns apiKey's client record, or ErrClientNotFound if none
+	// exists.
+	Lookup(ctx context.Context, apiKey string) (*ClientInfo, error)
+	// Upsert creates or replaces the client record for info.APIKey.
+	Upsert(ctx context.Context, info *ClientInfo) error
+	// Delete removes apiKey's client record, if any.
+	Delete(ctx context.Context, apiKey string) error
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedKeyStore wraps a KeyStore with an LRU+TTL cache so a hot API key
+// doesn't hit the backing store (Postgres or Redis) on every request.
+// Writes invalidate the cached entry rather than updating it in place, so a
+// stale record is never served after an admin edit.
+type CachedKeyStore struct {
+	backend KeyStore
+	ttl     time.Duration
+	maxSize int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type cachedKeyStoreEntry struct {
+	apiKey    string
+	info      *ClientInfo
+	expiresAt time.Time
+}
+
+// NewCachedKeyStore wraps backend with an LRU cache holding up to maxSize
+// entries, each valid for ttl.
+func NewCachedKeyStore(backend KeyStore, maxSize int, ttl time.Duration) *CachedKeyStore {
+	return &CachedKeyStore{
+		backend: backend,
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *CachedKeyStore) Lookup(ctx context.Context, apiKey string) (*ClientInfo, error) {
+	if info, ok := c.get(apiKey); ok {
+		return info, nil
+	}
+
+	info, err := c.backend.Lookup(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(apiKey, info)
+	return info, nil
+}
+
+func (c *CachedKeyStore) Upsert(ctx context.Context, info *ClientInfo) error {
+	if err := c.backend.Upsert(ctx, info); err != nil {
+		return err
+	}
+	c.evict(info.APIKey)
+	return nil
+}
+
+func (c *CachedKeyStore) Delete(ctx context.Context, apiKey string) error {
+	if err := c.backend.Delete(ctx, apiKey); err != nil {
+		return err
+	}
+	c.evict(apiKey)
+	return nil
+}
+
+func (c *CachedKeyStore) get(apiKey string) (*ClientInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[apiKey]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*cachedKeyStoreEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, apiKey)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return entry.info, true
+}
+
+func (c *CachedKeyStore) set(apiKey string, info *ClientInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[apiKey]; ok {
+		el.Value.(*cachedKeyStoreEntry).info = info
+		el.Value.(*cachedKeyStoreEntry).expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&cachedKeyStoreEntry{
+		apiKey:    apiKey,
+		info:      info,
+		expiresAt: time.Now().Add(c.ttl),
+	})
+	c.entries[apiKey] = el
+
+	if c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cachedKeyStoreEntry).apiKey)
+		}
+	}
+}
+
+func (c *CachedKeyStore) evict(apiKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[apiKey]; ok {
+		c.order.Remove(el)
+		delete(c.entries, apiKey)
+	}
+}
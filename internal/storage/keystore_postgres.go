@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresKeyStore is a KeyStore backed by Postgres, for deployments with
+// enough clients that a Redis hash per key stops being convenient to
+// administer. See migration/clients.sql for the table it expects.
+type PostgresKeyStore struct {
+	db *sql.DB
+}
+
+// NewPostgresKeyStore opens a connection pool to the Postgres instance
+// described by dsn and verifies it's reachable.
+func NewPostgresKeyStore(dsn string) (*PostgresKeyStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres connection: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+	}
+
+	return &PostgresKeyStore{db: db}, nil
+}
+
+// Close closes the underlying connection pool.
+func (s *PostgresKeyStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *PostgresKeyStore) Lookup(ctx context.Context, apiKey string) (*ClientInfo, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT api_key, tier, requests_per_minute, requests_per_hour, requests_per_day,
+		       monthly_quota, allowed_apis, disabled, unlimited
+		FROM clients
+		WHERE api_key = $1
+	`, apiKey)
+
+	info := &ClientInfo{}
+	var allowedAPIs string
+	if err := row.Scan(
+		&info.APIKey, &info.Tier, &info.RequestsPerMinute, &info.RequestsPerHour, &info.RequestsPerDay,
+		&info.MonthlyQuota, &allowedAPIs, &info.Disabled, &info.Unlimited,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to look up client %s: %w", apiKey, err)
+	}
+
+	info.AllowedAPIs = splitAllowedAPIs(allowedAPIs)
+	return info, nil
+}
+
+func (s *PostgresKeyStore) Upsert(ctx context.Context, info *ClientInfo) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO clients (api_key, tier, requests_per_minute, requests_per_hour, requests_per_day,
+		                      monthly_quota, allowed_apis, disabled, unlimited)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (api_key) DO UPDATE SET
+			tier = EXCLUDED.tier,
+			requests_per_minute = EXCLUDED.requests_per_minute,
+			requests_per_hour = EXCLUDED.requests_per_hour,
+			requests_per_day = EXCLUDED.requests_per_day,
+			monthly_quota = EXCLUDED.monthly_quota,
+			allowed_apis = EXCLUDED.allowed_apis,
+			disabled = EXCLUDED.disabled,
+			unlimited = EXCLUDED.unlimited
+	`, info.APIKey, info.Tier, info.RequestsPerMinute, info.RequestsPerHour, info.RequestsPerDay,
+		info.MonthlyQuota, joinAllowedAPIs(info.AllowedAPIs), info.Disabled, info.Unlimited)
+	if err != nil {
+		return fmt.Errorf("failed to upsert client %s: %w", info.APIKey, err)
+	}
+	return nil
+}
+
+func (s *PostgresKeyStore) Delete(ctx context.Context, apiKey string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM clients WHERE api_key = $1`, apiKey); err != nil {
+		return fmt.Errorf("failed to delete client %s: %w", apiKey, err)
+	}
+	return nil
+}
+
+func splitAllowedAPIs(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func joinAllowedAPIs(apis []string) string {
+	return strings.Join(apis, ",")
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisKeyStore is a KeyStore backed by a Redis hash per client, for small
+// deployments that don't want to run a separate Postgres instance.
+type RedisKeyStore struct {
+	client *redis.Client
+}
+
+// NewRedisKeyStore wraps an existing RedisClient's connection for client
+// record storage.
+func NewRedisKeyStore(redis *RedisClient) *RedisKeyStore {
+	return &RedisKeyStore{client: redis.client}
+}
+
+func clientKey(apiKey string) string {
+	return "client:" + apiKey
+}
+
+func (s *RedisKeyStore) Lookup(ctx context.Context, apiKey string) (*ClientInfo, error) {
+	fields, err := s.client.HGetAll(ctx, clientKey(apiKey)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up client %s: %w", apiKey, err)
+	}
+	if len(fields) == 0 {
+		return nil, ErrClientNotFound
+	}
+
+	info := &ClientInfo{
+		APIKey:            apiKey,
+		Tier:              fields["tier"],
+		RequestsPerMinute: atoiOrZero(fields["requests_per_minute"]),
+		RequestsPerHour:   atoiOrZero(fields["requests_per_hour"]),
+		RequestsPerDay:    atoiOrZero(fields["requests_per_day"]),
+		MonthlyQuota:      atoiOrZero(fields["monthly_quota"]),
+		AllowedAPIs:       splitAllowedAPIs(fields["allowed_apis"]),
+		Disabled:          fields["disabled"] == "1",
+		Unlimited:         fields["unlimited"] == "1",
+	}
+	return info, nil
+}
+
+func (s *RedisKeyStore) Upsert(ctx context.Context, info *ClientInfo) error {
+	fields := map[string]interface{}{
+		"tier":                info.Tier,
+		"requests_per_minute": info.RequestsPerMinute,
+		"requests_per_hour":   info.RequestsPerHour,
+		"requests_per_day":    info.RequestsPerDay,
+		"monthly_quota":       info.MonthlyQuota,
+		"allowed_apis":        joinAllowedAPIs(info.AllowedAPIs),
+		"disabled":            boolToFlag(info.Disabled),
+		"unlimited":           boolToFlag(info.Unlimited),
+	}
+	if err := s.client.HSet(ctx, clientKey(info.APIKey), fields).Err(); err != nil {
+		return fmt.Errorf("failed to upsert client %s: %w", info.APIKey, err)
+	}
+	return nil
+}
+
+func (s *RedisKeyStore) Delete(ctx context.Context, apiKey string) error {
+	if err := s.client.Del(ctx, clientKey(apiKey)).Err(); err != nil {
+		return fmt.Errorf("failed to delete client %s: %w", apiKey, err)
+	}
+	return nil
+}
+
+func atoiOrZero(s string) int {
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func boolToFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
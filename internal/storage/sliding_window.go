@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// slidingWindowCheckAndAddScript atomically trims expired entries, checks
+// the resulting count against limit, and only adds the current request as a
+// new member when still under it — closing the check-then-record race that
+// existed when the sliding window's check and its commit were separate round
+// trips, so a burst of concurrent requests can no longer all pass the check
+// and then all commit, overshooting the window's limit.
+// ARGV: [1]=now_ms [2]=window_ms [3]=limit [4]=member.
+// Returns {allowed (0/1), count, oldest_ms}.
+var slidingWindowCheckAndAddScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local windowMs = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now - windowMs)
+
+local count = redis.call("ZCARD", key)
+local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+local oldestMs = now
+if #oldest > 0 then
+	oldestMs = tonumber(oldest[2])
+end
+
+if count >= limit then
+	return {0, count, oldestMs}
+end
+
+redis.call("ZADD", key, now, member)
+redis.call("EXPIRE", key, math.ceil(windowMs / 1000) + 1)
+
+return {1, count + 1, oldestMs}
+`)
+
+// slidingWindowPeekScript trims expired entries and reports the resulting
+// count and oldest remaining member's score without adding a new one, for
+// read-only reporting (see RedisClient.PeekSlidingWindow).
+// ARGV: [1]=now_ms [2]=window_ms. Returns {count, oldest_ms}.
+var slidingWindowPeekScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local windowMs = tonumber(ARGV[2])
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now - windowMs)
+
+local count = redis.call("ZCARD", key)
+local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+local oldestMs = now
+if #oldest > 0 then
+	oldestMs = tonumber(oldest[2])
+end
+
+return {count, oldestMs}
+`)
+
+// slidingWindowSeq disambiguates members added within the same millisecond,
+// since ZADD requires a unique member per request.
+var slidingWindowSeq uint64
+
+// PeekSlidingWindow reports key's current member count and oldest member
+// timestamp for a sliding window of the given duration, trimming expired
+// entries but without recording a new request. Used for reporting (see
+// RateLimiter.GetRateLimitInfo) and for the peek phase of CheckRateLimit's
+// sliding-window check; the request itself is only recorded by the atomic
+// CheckAndAddSlidingWindow, which re-checks the limit when it commits.
+func (r *RedisClient) PeekSlidingWindow(ctx context.Context, key string, now time.Time, window time.Duration) (count int, oldest time.Time, err error) {
+	res, err := slidingWindowPeekScript.Run(ctx, r.client, []string{key}, now.UnixMilli(), window.Milliseconds()).Result()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to peek sliding window: %w", err)
+	}
+
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 2 {
+		return 0, time.Time{}, fmt.Errorf("unexpected sliding window script result: %v", res)
+	}
+	return int(vals[0].(int64)), time.UnixMilli(vals[1].(int64)), nil
+}
+
+// CheckAndAddSlidingWindow atomically checks key's sliding window count
+// against limit and, only if still under it, records the request, returning
+// whether it was allowed alongside the resulting count and oldest member
+// timestamp so RateLimiter can derive ResetTime as oldest+window instead of
+// the next wall-clock boundary. See RateLimiter.checkAndConsumeWindow.
+func (r *RedisClient) CheckAndAddSlidingWindow(ctx context.Context, key string, now time.Time, window time.Duration, limit int) (allowed bool, count int, oldest time.Time, err error) {
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), atomic.AddUint64(&slidingWindowSeq, 1))
+
+	res, err := slidingWindowCheckAndAddScript.Run(ctx, r.client, []string{key}, now.UnixMilli(), window.Milliseconds(), limit, member).Result()
+	if err != nil {
+		return false, 0, time.Time{}, fmt.Errorf("failed to check and add to sliding window: %w", err)
+	}
+
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 3 {
+		return false, 0, time.Time{}, fmt.Errorf("unexpected sliding window script result: %v", res)
+	}
+	return vals[0].(int64) == 1, int(vals[1].(int64)), time.UnixMilli(vals[2].(int64)), nil
+}
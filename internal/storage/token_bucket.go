@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tokenBucketScript atomically refills a token bucket stored as a Redis
+// hash ({tokens, last_refill_ns}) up to burst capacity at the given rate,
+// then consumes cost tokens if enough are available. ARGV: [1]=now_ns
+// [2]=rate_per_sec [3]=burst [4]=cost. Returns {allowed (0/1), tokens*1000}
+// (fixed-point, since Lua numbers returned to Redis are truncated to integers).
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+local data = redis.call("HMGET", key, "tokens", "last_refill_ns")
+local tokens = tonumber(data[1])
+local last = tonumber(data[2])
+
+if tokens == nil then
+	tokens = burst
+	last = now
+end
+
+local elapsed = (now - last) / 1e9
+if elapsed > 0 then
+	tokens = math.min(burst, tokens + elapsed * rate)
+	last = now
+end
+
+local allowed = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+redis.call("HMSET", key, "tokens", tokens, "last_refill_ns", last)
+
+local ttl = burst / rate
+if ttl < 1 then
+	ttl = 1
+end
+redis.call("EXPIRE", key, math.ceil(ttl) + 1)
+
+return {allowed, math.floor(tokens * 1000)}
+`)
+
+// TokenBucketResult is the outcome of a single token-bucket check-and-consume.
+type TokenBucketResult struct {
+	Allowed bool
+	Tokens  float64
+}
+
+// ConsumeToken atomically refills key's token bucket (rate tokens/sec, up to
+// burst capacity) and consumes cost tokens if available.
+func (r *RedisClient) ConsumeToken(ctx context.Context, key string, rate, burst, cost float64) (TokenBucketResult, error) {
+	res, err := tokenBucketScript.Run(ctx, r.client, []string{key}, time.Now().UnixNano(), rate, burst, cost).Result()
+	if err != nil {
+		return TokenBucketResult{}, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 2 {
+		return TokenBucketResult{}, fmt.Errorf("unexpected token bucket script result: %v", res)
+	}
+
+	return TokenBucketResult{
+		Allowed: vals[0].(int64) == 1,
+		Tokens:  float64(vals[1].(int64)) / 1000,
+	}, nil
+}
@@ -0,0 +1,111 @@
+// Package upstream implements a per-route pool of backend upstreams: active
+// health checking, weighted/round-robin/least-connections load balancing,
+// and per-backend circuit breaking, in place of GatewayHandler's single
+// static Target.URL.
+package upstream
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"rate-limiting-gateway/internal/breaker"
+)
+
+// State is a backend's health as tracked by its HealthChecker.
+type State int
+
+const (
+	// Healthy backends are eligible for selection.
+	Healthy State = iota
+	// Unhealthy backends are ejected from the pool until enough consecutive
+	// successful probes mark them healthy again.
+	Unhealthy
+	// Draining backends are still serving in-flight requests but excluded
+	// from new selections, for a graceful removal from the pool.
+	Draining
+)
+
+func (s State) String() string {
+	switch s {
+	case Unhealthy:
+		return "unhealthy"
+	case Draining:
+		return "draining"
+	default:
+		return "healthy"
+	}
+}
+
+// Backend is one upstream instance in a Pool: its address, LB weight,
+// health state, in-flight connection count (for least-connections), and its
+// own circuit breaker.
+type Backend struct {
+	URL    string
+	Weight int
+
+	breaker *breaker.CircuitBreaker
+
+	mu    sync.RWMutex
+	state State
+
+	conns int64
+}
+
+// NewBackend creates a backend for url with the given LB weight (minimum 1)
+// and circuit breaker configuration.
+func NewBackend(url string, weight int, breakerConfig breaker.Config) *Backend {
+	if weight < 1 {
+		weight = 1
+	}
+	return &Backend{
+		URL:     url,
+		Weight:  weight,
+		breaker: breaker.NewCircuitBreaker(breakerConfig),
+		state:   Healthy,
+	}
+}
+
+// State reports the backend's current health as tracked by its HealthChecker.
+func (b *Backend) State() State {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.state
+}
+
+func (b *Backend) setState(s State) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = s
+}
+
+// BreakerAllows reports whether the backend's circuit breaker currently
+// permits a request (see breaker.CircuitBreaker.Allow).
+func (b *Backend) BreakerAllows() bool {
+	return b.breaker.Allow()
+}
+
+// RecordResult reports a completed request's outcome to the backend's
+// circuit breaker.
+func (b *Backend) RecordResult(success bool) {
+	b.breaker.RecordResult(success)
+}
+
+// BreakerState reports the backend's circuit breaker state.
+func (b *Backend) BreakerState() breaker.State {
+	return b.breaker.State()
+}
+
+// acquire increments the backend's in-flight connection count, for
+// LeastConnectionsPicker.
+func (b *Backend) acquire() {
+	atomic.AddInt64(&b.conns, 1)
+}
+
+// release decrements the backend's in-flight connection count.
+func (b *Backend) release() {
+	atomic.AddInt64(&b.conns, -1)
+}
+
+func (b *Backend) activeConns() int64 {
+	return atomic.LoadInt64(&b.conns)
+}
@@ -0,0 +1,118 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"rate-limiting-gateway/internal/breaker"
+	"gopkg.in/yaml.v3"
+)
+
+// backendConfigEntry is the YAML shape of one pool member.
+type backendConfigEntry struct {
+	URL    string `yaml:"url"`
+	Weight int    `yaml:"weight,omitempty"`
+}
+
+// healthCheckConfigEntry is the YAML shape of a route's health check policy.
+type healthCheckConfigEntry struct {
+	Path               string        `yaml:"path,omitempty"`
+	Interval           time.Duration `yaml:"interval,omitempty"`
+	Timeout            time.Duration `yaml:"timeout,omitempty"`
+	HealthyThreshold   int           `yaml:"healthy_threshold,omitempty"`
+	UnhealthyThreshold int           `yaml:"unhealthy_threshold,omitempty"`
+}
+
+// routeConfigEntry is the YAML shape of one routed upstream pool.
+type routeConfigEntry struct {
+	Pattern     string                 `yaml:"pattern"`
+	Strategy    string                 `yaml:"strategy,omitempty"` // "round_robin" | "weighted_random" | "least_connections"
+	RetryBudget int                    `yaml:"retry_budget,omitempty"`
+	HealthCheck healthCheckConfigEntry `yaml:"health_check,omitempty"`
+	Backends    []backendConfigEntry   `yaml:"backends"`
+}
+
+// routeConfigFile is the top-level YAML document loaded by LoadRouteConfig.
+type routeConfigFile struct {
+	Routes []routeConfigEntry `yaml:"routes"`
+}
+
+// LoadRouteConfig reads a path-prefix route table from a YAML file on disk,
+// in the same config-file style as LoadPolicyConfig, building a Pool (with
+// its own health checker already running) and ReverseProxy for each route.
+func LoadRouteConfig(path string, logger *slog.Logger) (*Router, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upstream route config %s: %w", path, err)
+	}
+
+	var cfg routeConfigFile
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse upstream route config %s: %w", path, err)
+	}
+
+	router := &Router{}
+	for _, entry := range cfg.Routes {
+		if len(entry.Backends) == 0 {
+			return nil, fmt.Errorf("route %q has no backends", entry.Pattern)
+		}
+
+		backends := make([]*Backend, 0, len(entry.Backends))
+		for _, be := range entry.Backends {
+			backends = append(backends, NewBackend(be.URL, be.Weight, breaker.DefaultConfig()))
+		}
+
+		pool := NewPool(backends, pickerForStrategy(entry.Strategy))
+
+		hc := entry.HealthCheck
+		if hc.Path == "" {
+			hc.Path = "/health"
+		}
+		if hc.Interval <= 0 {
+			hc.Interval = 10 * time.Second
+		}
+		if hc.Timeout <= 0 {
+			hc.Timeout = 2 * time.Second
+		}
+		if hc.HealthyThreshold <= 0 {
+			hc.HealthyThreshold = 2
+		}
+		if hc.UnhealthyThreshold <= 0 {
+			hc.UnhealthyThreshold = 3
+		}
+
+		checker := NewHealthChecker(pool, HealthCheckConfig{
+			Path:               hc.Path,
+			Interval:           hc.Interval,
+			Timeout:            hc.Timeout,
+			HealthyThreshold:   hc.HealthyThreshold,
+			UnhealthyThreshold: hc.UnhealthyThreshold,
+		}, logger)
+		checker.Start(context.Background())
+
+		route := &Route{
+			Pattern:   entry.Pattern,
+			Pool:      pool,
+			Transport: NewPoolTransport(pool, entry.RetryBudget, logger),
+		}
+		route.ReverseProxy = newReverseProxy(route, logger)
+
+		router.routes = append(router.routes, route)
+	}
+
+	return router, nil
+}
+
+func pickerForStrategy(strategy string) Picker {
+	switch strategy {
+	case "weighted_random":
+		return &WeightedRandomPicker{}
+	case "least_connections":
+		return &LeastConnectionsPicker{}
+	default:
+		return &RoundRobinPicker{}
+	}
+}
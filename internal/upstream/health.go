@@ -0,0 +1,119 @@
+package upstream
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthCheckConfig controls an active health checker's probing policy.
+type HealthCheckConfig struct {
+	// Path is appended to each backend's URL for the probe request.
+	Path string
+	// Interval is how often every backend in the pool is probed.
+	Interval time.Duration
+	// Timeout bounds a single probe request.
+	Timeout time.Duration
+	// HealthyThreshold is how many consecutive successful probes an
+	// Unhealthy backend needs before it's marked Healthy again.
+	HealthyThreshold int
+	// UnhealthyThreshold is how many consecutive failed probes a Healthy
+	// backend tolerates before it's marked Unhealthy.
+	UnhealthyThreshold int
+}
+
+// HealthChecker periodically probes every backend in a Pool and flips its
+// State once enough consecutive probes agree, so a single flaky response
+// doesn't eject (or recover) a backend on its own.
+type HealthChecker struct {
+	pool   *Pool
+	config HealthCheckConfig
+	client *http.Client
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	streak map[string]int // consecutive probes of the backend's current verdict
+}
+
+// NewHealthChecker creates a health checker over pool.
+func NewHealthChecker(pool *Pool, config HealthCheckConfig, logger *slog.Logger) *HealthChecker {
+	return &HealthChecker{
+		pool:   pool,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		logger: logger,
+		streak: make(map[string]int),
+	}
+}
+
+// Start runs probes on config.Interval until ctx is done.
+func (h *HealthChecker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(h.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.probeAll(ctx)
+			}
+		}
+	}()
+}
+
+func (h *HealthChecker) probeAll(ctx context.Context) {
+	for _, b := range h.pool.Backends() {
+		go h.probe(ctx, b)
+	}
+}
+
+func (h *HealthChecker) probe(ctx context.Context, b *Backend) {
+	probeCtx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, strings.TrimRight(b.URL, "/")+h.config.Path, nil)
+	if err != nil {
+		h.logger.Error("Failed to build health probe request",
+			slog.String("backend", b.URL),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	resp, err := h.client.Do(req)
+	healthy := err == nil && resp.StatusCode < http.StatusInternalServerError
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	h.recordProbe(b, healthy)
+}
+
+func (h *HealthChecker) recordProbe(b *Backend, healthy bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	wasUnhealthy := b.State() == Unhealthy
+	if healthy == !wasUnhealthy {
+		// Consistent with the current state; reset the opposing streak.
+		h.streak[b.URL] = 0
+		return
+	}
+
+	h.streak[b.URL]++
+
+	if wasUnhealthy && healthy && h.streak[b.URL] >= h.config.HealthyThreshold {
+		b.setState(Healthy)
+		h.streak[b.URL] = 0
+		h.logger.Info("Backend marked healthy", slog.String("backend", b.URL))
+	} else if !wasUnhealthy && !healthy && h.streak[b.URL] >= h.config.UnhealthyThreshold {
+		b.setState(Unhealthy)
+		h.streak[b.URL] = 0
+		h.logger.Warn("Backend marked unhealthy", slog.String("backend", b.URL))
+	}
+}
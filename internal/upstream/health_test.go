@@ -0,0 +1,67 @@
+package upstream
+
+import (
+	"log/slog"
+	"testing"
+
+	"rate-limiting-gateway/internal/breaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHealthChecker(healthyThreshold, unhealthyThreshold int) (*HealthChecker, *Backend) {
+	b := NewBackend("http://backend-1", 1, breaker.Config{})
+	h := NewHealthChecker(nil, HealthCheckConfig{
+		HealthyThreshold:   healthyThreshold,
+		UnhealthyThreshold: unhealthyThreshold,
+	}, slog.Default())
+	return h, b
+}
+
+func TestHealthChecker_RecordProbe_MarksUnhealthyAfterConsecutiveFailures(t *testing.T) {
+	h, b := newTestHealthChecker(2, 3)
+
+	h.recordProbe(b, false)
+	h.recordProbe(b, false)
+	assert.Equal(t, Healthy, b.State(), "below UnhealthyThreshold the backend must stay healthy")
+
+	h.recordProbe(b, false)
+	assert.Equal(t, Unhealthy, b.State(), "UnhealthyThreshold consecutive failures should eject the backend")
+}
+
+func TestHealthChecker_RecordProbe_SingleSuccessResetsFailureStreak(t *testing.T) {
+	h, b := newTestHealthChecker(2, 3)
+
+	h.recordProbe(b, false)
+	h.recordProbe(b, false)
+	h.recordProbe(b, true) // resets the failure streak
+	h.recordProbe(b, false)
+	h.recordProbe(b, false)
+
+	assert.Equal(t, Healthy, b.State(), "an intervening success must reset the consecutive-failure streak")
+}
+
+func TestHealthChecker_RecordProbe_RecoversToHealthyAfterConsecutiveSuccesses(t *testing.T) {
+	h, b := newTestHealthChecker(2, 1)
+
+	h.recordProbe(b, false) // trips Unhealthy after a single configured failure
+	assert.Equal(t, Unhealthy, b.State())
+
+	h.recordProbe(b, true)
+	assert.Equal(t, Unhealthy, b.State(), "below HealthyThreshold it must stay unhealthy")
+
+	h.recordProbe(b, true)
+	assert.Equal(t, Healthy, b.State(), "HealthyThreshold consecutive successes should recover the backend")
+}
+
+func TestHealthChecker_RecordProbe_SingleFailureResetsRecoveryStreak(t *testing.T) {
+	h, b := newTestHealthChecker(3, 1)
+
+	h.recordProbe(b, false) // Unhealthy
+	h.recordProbe(b, true)
+	h.recordProbe(b, true)
+	h.recordProbe(b, false) // resets the recovery streak
+	h.recordProbe(b, true)
+	h.recordProbe(b, true)
+
+	assert.Equal(t, Unhealthy, b.State(), "an intervening failure must reset the consecutive-success streak")
+}
@@ -0,0 +1,82 @@
+package upstream
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+// ErrNoHealthyBackends is returned by a Picker (via Pool.Pick) when every
+// candidate backend is unhealthy or has an open circuit breaker.
+var ErrNoHealthyBackends = errors.New("upstream: no healthy backends available")
+
+// Picker selects one backend from a pool of already-filtered candidates
+// (healthy, breaker-allowed, not excluded by a prior retry attempt).
+type Picker interface {
+	Pick(candidates []*Backend) (*Backend, error)
+}
+
+// RoundRobinPicker cycles through candidates in order, regardless of weight.
+type RoundRobinPicker struct {
+	mu  sync.Mutex
+	idx int
+}
+
+// Pick returns the next candidate in rotation.
+func (p *RoundRobinPicker) Pick(candidates []*Backend) (*Backend, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoHealthyBackends
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	backend := candidates[p.idx%len(candidates)]
+	p.idx++
+	return backend, nil
+}
+
+// WeightedRandomPicker selects a candidate at random, proportional to its
+// configured Weight.
+type WeightedRandomPicker struct{}
+
+// Pick returns a randomly selected candidate, weighted by Backend.Weight.
+func (WeightedRandomPicker) Pick(candidates []*Backend) (*Backend, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoHealthyBackends
+	}
+
+	total := 0
+	for _, b := range candidates {
+		total += b.Weight
+	}
+
+	target := rand.Intn(total)
+	for _, b := range candidates {
+		target -= b.Weight
+		if target < 0 {
+			return b, nil
+		}
+	}
+
+	return candidates[len(candidates)-1], nil
+}
+
+// LeastConnectionsPicker selects the candidate with the fewest in-flight
+// requests.
+type LeastConnectionsPicker struct{}
+
+// Pick returns the candidate with the lowest active connection count.
+func (LeastConnectionsPicker) Pick(candidates []*Backend) (*Backend, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoHealthyBackends
+	}
+
+	best := candidates[0]
+	for _, b := range candidates[1:] {
+		if b.activeConns() < best.activeConns() {
+			best = b
+		}
+	}
+	return best, nil
+}
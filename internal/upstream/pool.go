@@ -0,0 +1,54 @@
+package upstream
+
+import "sync"
+
+// Pool is a named set of backends for one route, load-balanced by a Picker
+// and eligible for selection only while healthy and not tripped open.
+type Pool struct {
+	mu       sync.RWMutex
+	backends []*Backend
+	picker   Picker
+}
+
+// NewPool creates a pool over backends, balanced by picker.
+func NewPool(backends []*Backend, picker Picker) *Pool {
+	return &Pool{
+		backends: backends,
+		picker:   picker,
+	}
+}
+
+// Pick selects a backend, excluding any whose URL is present in exclude
+// (already tried by a prior retry attempt), any that are Unhealthy or
+// Draining, and any whose circuit breaker is open.
+func (p *Pool) Pick(exclude map[string]bool) (*Backend, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	candidates := make([]*Backend, 0, len(p.backends))
+	for _, b := range p.backends {
+		if exclude[b.URL] {
+			continue
+		}
+		if b.State() != Healthy {
+			continue
+		}
+		if !b.BreakerAllows() {
+			continue
+		}
+		candidates = append(candidates, b)
+	}
+
+	return p.picker.Pick(candidates)
+}
+
+// Backends returns a snapshot of every backend in the pool, regardless of
+// health, for health checking and admin inspection.
+func (p *Pool) Backends() []*Backend {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	out := make([]*Backend, len(p.backends))
+	copy(out, p.backends)
+	return out
+}
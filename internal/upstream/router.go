@@ -0,0 +1,68 @@
+package upstream
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+
+	"rate-limiting-gateway/internal/proxy"
+)
+
+// Route pairs one path-prefix pattern with the backend pool and reverse
+// proxy that serve it.
+type Route struct {
+	Pattern      string
+	Pool         *Pool
+	Transport    *PoolTransport
+	ReverseProxy *httputil.ReverseProxy
+}
+
+// Router matches an incoming request path to the Route whose Pattern is its
+// longest matching prefix, mirroring limiter.Policy's PerAPI matching, since
+// the gateway proxies through a single catch-all Gin route and the upstream
+// API identity lives in the incoming path rather than in Gin's own routing.
+type Router struct {
+	routes []*Route
+}
+
+// Match returns the route whose pattern is the longest prefix of path, or
+// false if none match.
+func (r *Router) Match(path string) (*Route, bool) {
+	var best *Route
+	bestLen := -1
+
+	for _, route := range r.routes {
+		if strings.HasPrefix(path, route.Pattern) && len(route.Pattern) > bestLen {
+			best = route
+			bestLen = len(route.Pattern)
+		}
+	}
+
+	return best, best != nil
+}
+
+// newReverseProxy builds a ReverseProxy that forwards through route's
+// PoolTransport, which picks (and, on failure, re-picks) the backend per
+// attempt, so the Director here only needs to handle headers, not the
+// target host.
+func newReverseProxy(route *Route, logger *slog.Logger) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			proxy.StripHopHeaders(req.Header)
+			proxy.SetForwardedHeaders(req)
+		},
+		Transport: route.Transport,
+		ModifyResponse: func(resp *http.Response) error {
+			proxy.StripHopHeaders(resp.Header)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Error("Upstream pool request failed",
+				slog.String("route", route.Pattern),
+				slog.Any("error", err),
+			)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+}
@@ -0,0 +1,128 @@
+package upstream
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// idempotentMethods are safe to retry against another backend without risk
+// of double-applying a side effect.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// PoolTransport is an http.RoundTripper that picks a backend from a Pool for
+// each request, retrying idempotent requests against another healthy
+// backend (up to RetryBudget additional attempts) on connect failures or a
+// 5xx response.
+type PoolTransport struct {
+	pool        *Pool
+	retryBudget int
+	base        http.RoundTripper
+	logger      *slog.Logger
+}
+
+// NewPoolTransport creates a transport over pool, retrying idempotent
+// requests up to retryBudget additional times against a different backend.
+func NewPoolTransport(pool *Pool, retryBudget int, logger *slog.Logger) *PoolTransport {
+	return &PoolTransport{
+		pool:        pool,
+		retryBudget: retryBudget,
+		base: &http.Transport{
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// RoundTrip picks a backend and forwards req to it, retrying against
+// another backend on failure when the method is idempotent and the retry
+// budget allows it.
+func (t *PoolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if idempotentMethods[req.Method] && t.retryBudget > 0 {
+		attempts += t.retryBudget
+	}
+
+	// A retried request needs a fresh body reader each attempt, since the
+	// previous attempt already drained req.Body. Buffer it once up front
+	// rather than leaving later attempts to silently send an empty body.
+	var bodyBytes []byte
+	if attempts > 1 && req.Body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to buffer request body for retry: %w", err)
+		}
+	}
+
+	tried := make(map[string]bool, attempts)
+	var lastErr error
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		backend, err := t.pool.Pick(tried)
+		if err != nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		tried[backend.URL] = true
+
+		if bodyBytes != nil {
+			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		}
+
+		resp, err := t.attempt(req, backend)
+		if err != nil {
+			backend.RecordResult(false)
+			lastErr = fmt.Errorf("backend %s: %w", backend.URL, err)
+			t.logger.Warn("Upstream request failed, retrying against another backend",
+				slog.String("backend", backend.URL),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		success := resp.StatusCode < http.StatusInternalServerError
+		backend.RecordResult(success)
+		if !success && attempt < attempts-1 {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("backend %s returned %d", backend.URL, resp.StatusCode)
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, lastErr
+}
+
+func (t *PoolTransport) attempt(req *http.Request, backend *Backend) (*http.Response, error) {
+	target, err := url.Parse(backend.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backend URL %q: %w", backend.URL, err)
+	}
+
+	outReq := req.Clone(req.Context())
+	outReq.URL.Scheme = target.Scheme
+	outReq.URL.Host = target.Host
+	outReq.Host = target.Host
+
+	backend.acquire()
+	defer backend.release()
+
+	return t.base.RoundTrip(outReq)
+}
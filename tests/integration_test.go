@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -20,7 +21,6 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"go.uber.org/zap"
 )
 
 // TestGatewayIntegration tests the complete gateway functionality
@@ -31,7 +31,7 @@ func TestGatewayIntegration(t *testing.T) {
 	}
 
 	// Setup
-	logger, _ := zap.NewDevelopment()
+	logger := slog.Default()
 	cfg := &config.Config{
 		Server: config.ServerConfig{
 			Port: "8080",
@@ -130,7 +130,6 @@ func TestGatewayIntegration(t *testing.T) {
 	{
 		proxy.Use(authMiddleware.Authenticate())
 		proxy.Use(rateLimitMiddleware.RateLimit())
-		proxy.Use(rateLimitMiddleware.IncrementRateLimit())
 		proxy.Any("/*path", gatewayHandler.Proxy)
 	}
 
@@ -287,7 +286,7 @@ func TestRateLimitHeaders(t *testing.T) {
 		t.Skip("Redis not available, skipping test")
 	}
 
-	logger, _ := zap.NewDevelopment()
+	logger := slog.Default()
 	cfg := &config.Config{
 		RateLimit: config.RateLimitConfig{
 			RequestsPerMinute: 10,